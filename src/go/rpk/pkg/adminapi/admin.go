@@ -70,7 +70,7 @@ func GetAuth(p *config.RpkProfile) (rpadmin.Auth, error) {
 func NewClient(fs afero.Fs, p *config.RpkProfile, opts ...rpadmin.Opt) (*rpadmin.AdminAPI, error) {
 	a := &p.AdminAPI
 
-	addrs := a.Addresses
+	addrs := a.Endpoints()
 	tc, err := a.TLS.Config(fs)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create admin api tls config: %v", err)
@@ -80,6 +80,10 @@ func NewClient(fs afero.Fs, p *config.RpkProfile, opts ...rpadmin.Opt) (*rpadmin
 		return nil, err
 	}
 
+	if t := a.ClientTimeout(); t != 0 {
+		// Prepended so that explicit options override the profile.
+		opts = append([]rpadmin.Opt{rpadmin.ClientTimeout(t)}, opts...)
+	}
 	return rpadmin.NewClient(addrs, tc, auth, p.FromCloud, opts...)
 }
 
@@ -92,7 +96,7 @@ func NewHostClient(fs afero.Fs, p *config.RpkProfile, host string) (*rpadmin.Adm
 	}
 
 	a := &p.AdminAPI
-	addrs := a.Addresses
+	addrs := a.Endpoints()
 	tc, err := a.TLS.Config(fs)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create admin api tls config: %v", err)
@@ -103,7 +107,7 @@ func NewHostClient(fs afero.Fs, p *config.RpkProfile, host string) (*rpadmin.Adm
 		if i < 0 || i >= len(addrs) {
 			return nil, fmt.Errorf("admin host %d is out of allowed range [0, %d)", i, len(addrs))
 		}
-		addrs = []string{addrs[0]}
+		addrs = []string{addrs[i]}
 	} else {
 		addrs = []string{host} // trust input is hostname (validate below)
 	}
@@ -112,5 +116,9 @@ func NewHostClient(fs afero.Fs, p *config.RpkProfile, host string) (*rpadmin.Adm
 	if err != nil {
 		return nil, err
 	}
-	return rpadmin.NewClient(addrs, tc, auth, p.FromCloud)
+	var opts []rpadmin.Opt
+	if t := a.ClientTimeout(); t != 0 {
+		opts = append(opts, rpadmin.ClientTimeout(t))
+	}
+	return rpadmin.NewClient(addrs, tc, auth, p.FromCloud, opts...)
 }
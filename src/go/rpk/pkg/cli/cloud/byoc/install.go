@@ -76,7 +76,7 @@ func loginAndEnsurePluginVersion(ctx context.Context, fs afero.Fs, cfg *config.C
 		token = overrides.CloudToken
 	} else {
 		priorProfile := cfg.ActualProfile()
-		_, authVir, clearedProfile, _, err := oauth.LoadFlow(ctx, fs, cfg, auth0.NewClient(cfg.DevOverrides()), false, false, cfg.DevOverrides().CloudAPIURL)
+		_, authVir, clearedProfile, _, err := oauth.LoadFlow(ctx, fs, cfg, auth0.NewClient(cfg.DevOverrides()).WithCloudURL(cfg.CloudAPIURL()), false, false, cfg.CloudAPIURL())
 		if err != nil {
 			return "", "", false, fmt.Errorf("unable to load the cloud token: %w. You may need to logout with 'rpk cloud logout --clear-credentials' and try again", err)
 		}
@@ -94,12 +94,8 @@ func loginAndEnsurePluginVersion(ctx context.Context, fs afero.Fs, cfg *config.C
 	}
 
 	// If not, we query the Cloud API for the plugin.
-	cloudURL := cloudapi.ProdURL
-	if u := overrides.CloudAPIURL; u != "" {
-		cloudURL = u
-	}
 	// Check our current version of the plugin.
-	cl := cloudapi.NewClient(cloudURL, token)
+	cl := cloudapi.NewClient(cfg.CloudAPIURL(), token)
 	var pack cloudapi.InstallPack
 	if isLatest {
 		pack, err = cl.LatestInstallPack(ctx)
@@ -43,7 +43,7 @@ func deleteCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 			cfg, err := p.Load(fs)
 			out.MaybeDie(err, "rpk unable to load config: %v", err)
 			priorProfile := cfg.ActualProfile()
-			_, authVir, clearedProfile, _, err := oauth.LoadFlow(cmd.Context(), fs, cfg, auth0.NewClient(cfg.DevOverrides()), false, false, cfg.DevOverrides().CloudAPIURL)
+			_, authVir, clearedProfile, _, err := oauth.LoadFlow(cmd.Context(), fs, cfg, auth0.NewClient(cfg.DevOverrides()).WithCloudURL(cfg.CloudAPIURL()), false, false, cfg.CloudAPIURL())
 			out.MaybeDie(err, "unable to authenticate with Redpanda Cloud: %v", err)
 
 			oauth.MaybePrintSwapMessage(clearedProfile, priorProfile, authVir)
@@ -46,18 +46,22 @@ func newPurgeCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 			out.MaybeDie(err, "rpk unable to load config: %v", err)
 
 			y, ok := cfg.ActualRpkYaml()
-			if !ok || y.Profile(common.ContainerProfileName) == nil {
+			if !ok || !y.HasProfile(common.ContainerProfileName) {
 				// rpk.yaml file nor profile exist, we exit.
 				return
 			}
-			cleared, err := profile.DeleteProfile(fs, y, common.ContainerProfileName)
+			wasCurrent, err := profile.DeleteProfile(fs, y, common.ContainerProfileName)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "unable to delete %q profile: %v; you may delete the profile manually running 'rpk profile delete %v'", common.ContainerProfileName, err, common.ContainerProfileName)
 				return
 			}
 			fmt.Printf("Deleted profile %q.\n", common.ContainerProfileName)
-			if cleared {
-				fmt.Println("This was the selected profile; rpk will use defaults until a new profile is selected or a new container is created.")
+			if wasCurrent {
+				if y.CurrentProfile != "" {
+					fmt.Printf("This was the selected profile; rpk selected the most recently used profile %q.\n", y.CurrentProfile)
+				} else {
+					fmt.Println("This was the selected profile; rpk will use defaults until a new profile is selected or a new container is created.")
+				}
 			}
 		},
 	}
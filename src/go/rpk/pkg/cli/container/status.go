@@ -35,7 +35,7 @@ func newStatusCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 
 			y, ok := cfg.ActualRpkYaml()
 			var withProfile bool
-			if ok && y.Profile(common.ContainerProfileName) != nil && y.CurrentProfile == common.ContainerProfileName {
+			if ok && y.HasProfile(common.ContainerProfileName) && y.CurrentProfile == common.ContainerProfileName {
 				withProfile = true
 			}
 			renderClusterInteract(nodes, withProfile)
@@ -0,0 +1,57 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package profile
+
+import (
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCommand(fs afero.Fs, p *config.Params) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Check that the current profile's endpoints are reachable",
+		Long: `Check that the current profile's endpoints are reachable.
+
+This command dials every Kafka broker, admin API address, and schema registry
+address in the current profile (including any flag or environment variable
+overrides) and reports whether each one accepts a TCP connection. This does
+not perform a TLS handshake nor authenticate; it only checks the network path.
+
+This command exits with a non-zero status if any endpoint is unreachable.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "rpk unable to load config: %v", err)
+
+			results := cfg.VirtualProfile().CheckConnectivity(cmd.Context())
+			if len(results) == 0 {
+				out.Die("the current profile has no addresses to check")
+			}
+
+			tw := out.NewTable("api", "address", "status", "error")
+			var failed bool
+			for _, r := range results {
+				status, errMsg := "ok", ""
+				if !r.Reachable {
+					status, errMsg, failed = "unreachable", r.Err.Error(), true
+				}
+				tw.Print(r.API, r.Address, status, errMsg)
+			}
+			tw.Flush()
+			if failed {
+				out.Die("\nsome endpoints are unreachable")
+			}
+		},
+	}
+}
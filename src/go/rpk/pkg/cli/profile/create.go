@@ -339,7 +339,11 @@ func createCloudProfile(ctx context.Context, yAuthVir *config.RpkCloudAuth, cfg
 	if expired {
 		return CloudClusterOutputs{}, errors.New("current cloud auth has expired, please re-login with 'rpk cloud login'")
 	}
-	cl := cloudapi.NewClient(overrides.CloudAPIURL, yAuthVir.AuthToken, httpapi.ReqTimeout(10*time.Second))
+	cloudURL := yAuthVir.CloudAPIURL()
+	if overrides.CloudAPIURL != "" {
+		cloudURL = overrides.CloudAPIURL
+	}
+	cl := cloudapi.NewClient(cloudURL, yAuthVir.AuthToken, httpapi.ReqTimeout(10*time.Second))
 
 	cpCl, err := publicapi.NewControlPlaneClientSet(cfg.DevOverrides().PublicAPIURL, yAuthVir.AuthToken)
 	if err != nil {
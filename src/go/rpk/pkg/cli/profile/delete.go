@@ -25,8 +25,10 @@ func newDeleteCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 		Long: `Delete an rpk profile.
 
 Deleting a profile removes it from the rpk.yaml file. If the deleted profile
-was the selected profile, rpk will use in-memory defaults until a new profile
-is selected.
+was the selected profile, the most recently used remaining profile is
+selected; profiles that were last used at the same time (or never used) are
+chosen between alphabetically. If no profiles remain, rpk will use in-memory
+defaults until a new profile is created.
 `,
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: ValidProfiles(fs, p),
@@ -40,38 +42,36 @@ is selected.
 			}
 
 			name := args[0]
-			cleared, err := DeleteProfile(fs, y, name)
+			wasCurrent, err := DeleteProfile(fs, y, name)
 			out.MaybeDieErr(err)
 			fmt.Printf("Deleted profile %q.\n", name)
-			if cleared {
-				fmt.Println("This was the selected profile; rpk will use defaults until a new profile is selected.")
+			if wasCurrent {
+				if y.CurrentProfile != "" {
+					fmt.Printf("This was the selected profile; rpk selected the most recently used profile %q.\n", y.CurrentProfile)
+				} else {
+					fmt.Println("This was the selected profile; rpk will use defaults until a new profile is selected.")
+				}
 			}
 		},
 	}
 }
 
+// DeleteProfile deletes the named profile and writes the rpk.yaml, returning
+// whether the profile was the current profile. If it was, the most recently
+// used remaining profile is now current; see RpkYaml.DeleteProfile.
 func DeleteProfile(
 	fs afero.Fs,
 	y *config.RpkYaml,
 	name string,
-) (cleared bool, err error) {
-	idx := -1
-	for i, p := range y.Profiles {
-		if p.Name == name {
-			idx = i
-			break
-		}
+) (wasCurrent bool, err error) {
+	if p := y.Profile(name); p != nil {
+		wasCurrent = y.CurrentProfile == p.Name
 	}
-	if idx == -1 {
-		return false, fmt.Errorf("profile %q does not exist", name)
-	}
-	y.Profiles = append(y.Profiles[:idx], y.Profiles[idx+1:]...)
-	if y.CurrentProfile == name {
-		y.CurrentProfile = ""
-		cleared = true
+	if err := y.DeleteProfile(name); err != nil {
+		return false, err
 	}
 	if err := y.Write(fs); err != nil {
 		return false, fmt.Errorf("unable to write rpk file: %v", err)
 	}
-	return cleared, nil
+	return wasCurrent, nil
 }
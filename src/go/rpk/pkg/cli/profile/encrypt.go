@@ -0,0 +1,54 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package profile
+
+import (
+	"fmt"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newEncryptCommand(fs afero.Fs, p *config.Params) *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the secrets in your rpk.yaml",
+		Long: fmt.Sprintf(`Encrypt the secrets in your rpk.yaml.
+
+This command encrypts every secret in your rpk.yaml (SASL passwords, and cloud
+auth tokens and client secrets) with a key derived from the passphrase in the
+%[1]s environment variable.
+
+Once encrypted, rpk requires %[1]s to load the rpk.yaml, and every
+later write of the rpk.yaml, such as a cloud token refresh or
+'rpk profile set', keeps the secrets encrypted. Secrets that are already
+encrypted are left as is.
+`, config.EnvConfigKey),
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "rpk unable to load config: %v", err)
+
+			y, ok := cfg.ActualRpkYaml()
+			if !ok {
+				out.Die("rpk.yaml file does not exist")
+			}
+			key := p.EffectiveConfigKey()
+			if len(key) == 0 {
+				out.Die("%s must be set to the passphrase to encrypt secrets with", config.EnvConfigKey)
+			}
+			err = y.WriteEncrypted(fs, key)
+			out.MaybeDie(err, "unable to write encrypted rpk.yaml: %v", err)
+			fmt.Printf("Encrypted the secrets in %s.\n", y.FileLocation())
+		},
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newEnvCommand(fs afero.Fs, p *config.Params) *cobra.Command {
+	return &cobra.Command{
+		Use:   "env",
+		Short: "Print the current profile as shell environment variables",
+		Long: `Print the current profile as shell environment variables.
+
+This command prints export statements for the connection settings of the
+current profile, using the RPK_* environment variables that rpk itself reads
+(see 'rpk -X help'). You can use this to carry a profile into a shell or
+another tool:
+
+    eval $(rpk profile env)
+
+The output includes your SASL password in plaintext, since it is meant for
+use in a local shell.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			p, err := p.LoadVirtualProfile(fs)
+			out.MaybeDie(err, "rpk unable to load config: %v", err)
+
+			for _, kv := range p.Environ() {
+				k, v, _ := strings.Cut(kv, "=")
+				fmt.Printf("export %s=%s\n", k, shellquote.Join(v))
+			}
+		},
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand(fs afero.Fs, p *config.Params) *cobra.Command {
+	var setCurrent bool
+	cmd := &cobra.Command{
+		Use:   "import [FILE]",
+		Short: "Import the profiles and cloud auths from another rpk.yaml",
+		Long: `Import the profiles and cloud auths from another rpk.yaml.
+
+This command merges every profile and cloud auth in FILE into your rpk.yaml.
+If a profile with the same name, or a cloud auth for the same organization and
+kind, already exists, the existing one is kept and the collision is reported.
+
+By default, your current profile and cloud auth are kept. Use --set-current to
+switch to the current profile and cloud auth of the imported file.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cfg, err := p.Load(fs)
+			out.MaybeDie(err, "rpk unable to load config: %v", err)
+
+			y, err := cfg.ActualRpkYamlOrEmpty()
+			out.MaybeDie(err, "unable to load rpk.yaml: %v", err)
+
+			r, err := y.MergeFile(fs, args[0], setCurrent)
+			out.MaybeDie(err, "unable to import %s: %v", args[0], err)
+
+			err = y.Write(fs)
+			out.MaybeDie(err, "unable to write rpk.yaml: %v", err)
+
+			for _, s := range []struct {
+				what  string
+				names []string
+			}{
+				{"Imported profiles", r.AddedProfiles},
+				{"Skipped existing profiles", r.SkippedProfiles},
+				{"Imported cloud auths", r.AddedAuths},
+				{"Skipped existing cloud auths", r.SkippedAuths},
+			} {
+				if len(s.names) > 0 {
+					fmt.Printf("%s: %s\n", s.what, strings.Join(s.names, ", "))
+				}
+			}
+			if setCurrent {
+				fmt.Printf("Current profile is %q.\n", y.CurrentProfile)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&setCurrent, "set-current", false, "Switch to the current profile and cloud auth of the imported file")
+	return cmd
+}
@@ -10,8 +10,6 @@
 package profile
 
 import (
-	"sort"
-
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
 	"github.com/spf13/afero"
@@ -36,11 +34,7 @@ func newListCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 				return
 			}
 
-			sort.Slice(y.Profiles, func(i, j int) bool {
-				return y.Profiles[i].Name < y.Profiles[j].Name
-			})
-
-			for _, p := range y.Profiles {
+			for _, p := range y.SortedProfiles() {
 				name := p.Name
 				if name == y.CurrentProfile {
 					name += "*"
@@ -32,16 +32,25 @@ profiles for multiple clusters and swap between them with 'rpk profile use'.
 Multiple profiles may be useful if, for example, you use rpk to talk to
 a localhost cluster, a dev cluster, and a prod cluster, and you want to keep
 your configuration in one place.
+
+A profile can inherit the settings of another profile by setting its parent
+field. Any fields set in the profile itself take precedence over the fields of
+its parent, which allows many profiles to share SASL and TLS settings while
+differing only in their brokers.
 `,
 	}
 
 	cmd.AddCommand(
+		newCheckCommand(fs, p),
 		newCreateCommand(fs, p),
 		newClearCommand(fs, p),
 		newCurrentCommand(fs, p),
 		newDeleteCommand(fs, p),
 		newEditCommand(fs, p),
 		newEditGlobalsCommand(fs, p),
+		newEncryptCommand(fs, p),
+		newEnvCommand(fs, p),
+		newImportCommand(fs, p),
 		newListCommand(fs, p),
 		newPrintCommand(fs, p),
 		newPrintGlobalsCommand(fs, p),
@@ -68,10 +77,9 @@ func ValidProfiles(fs afero.Fs, p *config.Params) func(*cobra.Command, []string,
 			return nil, cobra.ShellCompDirectiveDefault
 		}
 		var names []string
-		for i := range y.Profiles {
-			p := &y.Profiles[i]
-			if strings.HasPrefix(p.Name, toComplete) {
-				names = append(names, p.Name)
+		for _, name := range y.ProfileNames() {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
 			}
 		}
 		return names, cobra.ShellCompDirectiveDefault
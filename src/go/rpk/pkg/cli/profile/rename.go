@@ -33,17 +33,10 @@ func newRenameToCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 				out.Die("rpk.yaml file does not exist")
 			}
 
-			p := y.Profile(y.CurrentProfile)
-			if p == nil {
-				out.Die("current profile %q does not exist", y.CurrentProfile)
-				return
-			}
 			to := args[0]
-			if y.Profile(to) != nil {
-				out.Die("destination profile %q already exists", to)
-			}
-			p.Name = to
-			y.CurrentProfile = to
+			err = y.RenameProfile(y.CurrentProfile, to)
+			out.MaybeDie(err, "unable to rename the current profile: %v", err)
+			p := y.Profile(to)
 			priorAuth, currentAuth := y.MoveProfileToFront(&p)
 			err = y.Write(fs)
 			out.MaybeDieErr(err)
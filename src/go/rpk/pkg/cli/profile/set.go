@@ -11,6 +11,7 @@ package profile
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
@@ -20,7 +21,8 @@ import (
 )
 
 func newSetCommand(fs afero.Fs, p *config.Params) *cobra.Command {
-	return &cobra.Command{
+	var dryRun bool
+	cmd := &cobra.Command{
 		Use:   "set [KEY=VALUE]+",
 		Short: "Set fields in the current rpk profile",
 		Long: `Set fields in the current rpk profile.
@@ -39,6 +41,9 @@ format. If you begin writing a YAML path, this command will suggest the rest of
 the path.
 
 You can also use the format 'set key value' if you intend to only set one key.
+
+With --dry-run, this command prints the resulting rpk.yaml, with secrets
+redacted, and whether the file would change, without writing anything.
 `,
 
 		Args:              cobra.MinimumNArgs(1),
@@ -57,17 +62,30 @@ You can also use the format 'set key value' if you intend to only set one key.
 			y, err := cfg.ActualRpkYamlOrEmpty()
 			out.MaybeDie(err, "unable to load rpk.yaml: %v", err)
 
-			p := y.Profile(y.CurrentProfile)
-			if p == nil {
-				out.Die("current profile %q does not exist", y.CurrentProfile)
-			}
+			p, err := y.ResolveCurrentProfile()
+			out.MaybeDieErr(err)
 			err = doSet(p, args)
 			out.MaybeDieErr(err)
+			if dryRun {
+				changed, _, err := y.WouldChange(fs)
+				out.MaybeDieErr(err)
+				redacted := y.Redacted()
+				_, err = redacted.WriteTo(os.Stdout)
+				out.MaybeDieErr(err)
+				if changed {
+					fmt.Printf("\nProfile %q would be updated (dry run, nothing written).\n", p.Name)
+				} else {
+					fmt.Printf("\nProfile %q would not change.\n", p.Name)
+				}
+				return
+			}
 			err = y.Write(fs)
 			out.MaybeDieErr(err)
-			fmt.Printf("Profile %q updated successfully.\n", y.CurrentProfile)
+			fmt.Printf("Profile %q updated successfully.\n", p.Name)
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the resulting rpk.yaml and whether it would change, without writing it")
+	return cmd
 }
 
 func doSet(p *config.RpkProfile, set []string) error {
@@ -98,7 +116,8 @@ func validSetArgs(_ *cobra.Command, _ []string, toComplete string) (ps []string,
 	}()
 
 	xf, ypaths := config.XProfileFlags()
-	ypaths = append(ypaths, "description", "prompt") // we have no xflag for the description nor prompt field, the latter is a global that can also be edited per profile
+	ypaths = append(ypaths, "description", "parent", "aliases", "prompt", "read_only", "insecure") // we have no xflag for the description, parent, aliases, prompt, read_only, nor insecure fields, prompt is a global that can also be edited per profile
+	ypaths = append(ypaths, "kafka_api.dial_timeout", "kafka_api.request_timeout_overhead", "kafka_api.sasl.oauth.token_command", "kafka_api.sasl.oauth.timeout", "kafka_api.sasl.gssapi.keytab", "kafka_api.sasl.gssapi.principal", "kafka_api.sasl.gssapi.service_name", "kafka_api.sasl.gssapi.realm", "kafka_api.sasl.gssapi.username", "kafka_api.sasl.gssapi.password", "kafka_api.client_tuning.max_in_flight", "kafka_api.client_tuning.conn_idle_timeout", "kafka_api.client_tuning.keep_alive", "admin_api.dial_timeout", "admin_api.request_timeout")
 	if len(toComplete) == 0 {
 		return ypaths, cobra.ShellCompDirectiveNoSpace
 	}
@@ -10,7 +10,9 @@
 package profile
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/out"
@@ -34,10 +36,17 @@ func newUseCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 			}
 
 			name := args[0]
-			p := y.Profile(name)
-			if p == nil {
-				out.Die("profile %q does not exist", name)
+			p, err := y.SetCurrentProfile(name)
+			if errors.Is(err, config.ErrProfileNotFound) {
+				if matches := y.FindProfiles(name); len(matches) > 0 {
+					var names []string
+					for _, m := range matches {
+						names = append(names, m.Name)
+					}
+					out.Die("%v; did you mean %s?", err, strings.Join(names, ", "))
+				}
 			}
+			out.MaybeDieErr(err)
 			priorAuth, currentAuth := y.MoveProfileToFront(&p)
 
 			err = y.Write(fs)
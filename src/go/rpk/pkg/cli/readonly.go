@@ -0,0 +1,125 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cobraext"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyGuarded lists every command, by path below "rpk", that modifies
+// the cluster a profile talks to. These commands refuse to run against a read
+// only profile. New mutating commands must be added here.
+var readOnlyGuarded = []string{
+	"acl create",
+	"acl delete",
+	"acl user create",
+	"acl user delete",
+	"acl user update",
+
+	"cluster config edit",
+	"cluster config force-reset",
+	"cluster config import",
+	"cluster config set",
+	"cluster license set",
+	"cluster maintenance disable",
+	"cluster maintenance enable",
+	"cluster partitions disable",
+	"cluster partitions enable",
+	"cluster partitions move",
+	"cluster partitions move-cancel",
+	"cluster partitions movement-cancel",
+	"cluster partitions transfer-leadership",
+	"cluster partitions unsafe-recover",
+	"cluster quotas alter",
+	"cluster quotas import",
+	"cluster self-test start",
+	"cluster self-test stop",
+	"cluster storage restore start",
+
+	"group delete",
+	"group offset-delete",
+	"group seek",
+
+	"redpanda admin brokers decommission",
+	"redpanda admin brokers recommission",
+	"redpanda admin config log-level set",
+
+	"registry compatibility-level set",
+	"registry schema create",
+	"registry schema delete",
+	"registry subject delete",
+
+	"security acl create",
+	"security acl delete",
+	"security role assign",
+	"security role create",
+	"security role delete",
+	"security role unassign",
+	"security user create",
+	"security user delete",
+	"security user update",
+
+	"topic add-partitions",
+	"topic alter-config",
+	"topic create",
+	"topic delete",
+	"topic produce",
+	"topic trim-prefix",
+
+	"transform delete",
+	"transform deploy",
+	"transform pause",
+	"transform resume",
+}
+
+// readOnlyAnnotation marks a command wrapped by guardReadOnly.
+const readOnlyAnnotation = "rpk_read_only_guarded"
+
+// guardReadOnly wraps every command in readOnlyGuarded so that it exits
+// before running if the loaded profile is read only. If the profile cannot
+// be loaded, the command runs as is and reports the load error itself.
+func guardReadOnly(fs afero.Fs, p *config.Params, root *cobra.Command) {
+	guarded := make(map[string]bool, len(readOnlyGuarded))
+	for _, path := range readOnlyGuarded {
+		guarded[path] = true
+	}
+	check := func() {
+		if prof, err := p.LoadVirtualProfile(fs); err == nil {
+			config.CheckExitReadOnly(prof)
+		}
+	}
+	cobraext.Walk(root, func(c *cobra.Command) {
+		path := strings.TrimPrefix(c.CommandPath(), root.Name()+" ")
+		if !guarded[path] {
+			return
+		}
+		if run := c.Run; run != nil {
+			c.Run = func(cmd *cobra.Command, args []string) {
+				check()
+				run(cmd, args)
+			}
+		}
+		if runE := c.RunE; runE != nil {
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				check()
+				return runE(cmd, args)
+			}
+		}
+		if c.Annotations == nil {
+			c.Annotations = make(map[string]string)
+		}
+		c.Annotations[readOnlyAnnotation] = "true"
+	})
+}
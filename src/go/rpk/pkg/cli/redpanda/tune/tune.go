@@ -91,6 +91,8 @@ To learn more about a tuner, run 'rpk redpanda tune help <tuner name>'.
 			tunerParams.CPUMask = cpuMask
 			y, err := p.LoadVirtualRedpandaYaml(fs)
 			out.MaybeDie(err, "rpk unable to load config: %v", err)
+			err = y.Rpk.Tuners.Validate()
+			out.MaybeDie(err, "invalid tuner configuration: %v", err)
 			var tunerFactory factory.TunersFactory
 			if outTuneScriptFile != "" {
 				exists, err := afero.Exists(fs, outTuneScriptFile)
@@ -105,26 +105,7 @@ func Execute() {
 	})
 	root.RegisterFlagCompletionFunc("profile", profile.ValidProfiles(fs, p))
 
-	root.AddCommand(
-		acl.NewCommand(fs, p),
-		cloud.NewCommand(fs, p, osExec),
-		cluster.NewCommand(fs, p),
-		container.NewCommand(fs, p),
-		profile.NewCommand(fs, p),
-		debug.NewCommand(fs, p),
-		generate.NewCommand(fs, p),
-		group.NewCommand(fs, p),
-		plugincmd.NewCommand(fs),
-		registry.NewCommand(fs, p),
-		security.NewCommand(fs, p),
-		topic.NewCommand(fs, p),
-		transform.NewCommand(fs, p, osExec),
-		version.NewCommand(fs, p),
-
-		newStatusCommand(), // deprecated
-	)
-
-	addPlatformDependentCmds(fs, p, root)
+	addCommands(fs, p, root)
 
 	// Plugin autocompletion: Cobra creates autocompletion for shells via
 	// all commands discoverable from the root command. Plugins that are
@@ -184,6 +165,32 @@ func Execute() {
 	}
 }
 
+// addCommands adds every rpk command to root, and refuses to run the commands
+// listed in readOnlyGuarded against a read only profile.
+func addCommands(fs afero.Fs, p *config.Params, root *cobra.Command) {
+	root.AddCommand(
+		acl.NewCommand(fs, p),
+		cloud.NewCommand(fs, p, osExec),
+		cluster.NewCommand(fs, p),
+		container.NewCommand(fs, p),
+		profile.NewCommand(fs, p),
+		debug.NewCommand(fs, p),
+		generate.NewCommand(fs, p),
+		group.NewCommand(fs, p),
+		plugincmd.NewCommand(fs),
+		registry.NewCommand(fs, p),
+		security.NewCommand(fs, p),
+		topic.NewCommand(fs, p),
+		transform.NewCommand(fs, p, osExec),
+		version.NewCommand(fs, p),
+
+		newStatusCommand(), // deprecated
+	)
+
+	addPlatformDependentCmds(fs, p, root)
+	guardReadOnly(fs, p, root)
+}
+
 func osExec(path string, args []string) error {
 	args = append([]string{path}, args...)
 	env := os.Environ()
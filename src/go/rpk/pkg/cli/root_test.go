@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cobraext"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
@@ -65,3 +70,25 @@ func TestTrackHelp(t *testing.T) {
 	exp.inner["foo"].help = pluginHelp{Short: "updated foo help"}
 	assert.Equal(t, exp, base, "expected trackHelp to create perform in-place update to existing incomplete top level-foo")
 }
+
+func TestGuardReadOnly(t *testing.T) {
+	root := &cobra.Command{Use: "rpk"}
+	addCommands(afero.NewMemMapFs(), new(config.Params), root)
+
+	paths := make(map[string]*cobra.Command)
+	cobraext.Walk(root, func(c *cobra.Command) {
+		paths[strings.TrimPrefix(c.CommandPath(), "rpk ")] = c
+	})
+	for _, path := range readOnlyGuarded {
+		c, ok := paths[path]
+		if !ok && runtime.GOOS != "linux" && strings.HasPrefix(path, "redpanda ") {
+			continue
+		}
+		if assert.True(t, ok, "guarded command %q does not exist", path) {
+			assert.Equal(t, "true", c.Annotations[readOnlyAnnotation], "command %q is not guarded", path)
+		}
+	}
+	for _, path := range []string{"topic list", "topic consume", "cluster config get", "profile set"} {
+		assert.Empty(t, paths[path].Annotations[readOnlyAnnotation], "read only command %q is guarded", path)
+	}
+}
@@ -101,6 +101,9 @@ func newConsumeCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 
 			err = c.parseOffset(offset, topics, adm)
 			out.MaybeDie(err, "invalid --offset %q: %v", offset, err)
+			if g := p.DefaultConsumerGroup(); g != "" && !cmd.Flags().Changed("group") && len(c.partitions) == 0 && c.partEnds == nil {
+				c.group = g
+			}
 			if allEmpty := c.filterEmptyPartitions(); allEmpty {
 				return
 			}
@@ -155,7 +158,7 @@ func newConsumeCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 	cmd.Flags().Int32SliceVarP(&c.partitions, "partitions", "p", nil, "Comma delimited list of specific partitions to consume")
 	cmd.Flags().BoolVarP(&c.regex, "regex", "r", false, "Parse topics as regex; consume any topic that matches any expression")
 
-	cmd.Flags().StringVarP(&c.group, "group", "g", "", "Group to use for consuming (incompatible with -p)")
+	cmd.Flags().StringVarP(&c.group, "group", "g", "", "Group to use for consuming (incompatible with -p); defaults to the current profile's defaults.group")
 	cmd.Flags().StringVarP(&c.balancer, "balancer", "b", "cooperative-sticky", "Group balancer to use if group consuming (range, roundrobin, sticky, cooperative-sticky)")
 
 	cmd.Flags().Int32Var(&c.fetchMaxBytes, "fetch-max-bytes", 1<<20, "Maximum amount of bytes per fetch request per broker")
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/kafka"
@@ -48,12 +49,28 @@ For example,
 
 will create two topics, foo and bar, each with 20 partitions, 3 replicas, and
 the cleanup.policy=compact config option set.
+
+If the current profile has defaults.partitions set, topics are created with
+that many partitions unless --partitions is specified. If the current profile
+has defaults.topic_prefix set, the prefix is prepended to each topic name that
+does not already start with it.
 `,
 
 		Run: func(cmd *cobra.Command, topics []string) {
 			p, err := p.LoadVirtualProfile(fs)
 			out.MaybeDie(err, "rpk unable to load config: %v", err)
 
+			if d := p.ProfileDefaults; d.Partitions > 0 && !cmd.Flags().Changed("partitions") {
+				partitions = int32(d.Partitions)
+			}
+			if prefix := p.ProfileDefaults.TopicPrefix; prefix != "" {
+				for i, topic := range topics {
+					if !strings.HasPrefix(topic, prefix) {
+						topics[i] = prefix + topic
+					}
+				}
+			}
+
 			cl, err := kafka.NewFranzClient(fs, p)
 			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
 			defer cl.Close()
@@ -156,6 +156,12 @@ func newProduceCommand(fs afero.Fs, p *config.Params) *cobra.Command {
 			p, err := p.LoadVirtualProfile(fs)
 			out.MaybeDie(err, "rpk unable to load config: %v", err)
 
+			// More than one produce request in flight is only
+			// allowed if idempotency is disabled.
+			if t := p.KafkaAPI.ClientTuning; acks != -1 && t != nil && t.MaxInFlight > 0 {
+				opts = append(opts, kgo.MaxProduceRequestsInflightPerBroker(t.MaxInFlight))
+			}
+
 			cl, err := kafka.NewFranzClient(fs, p, opts...)
 			out.MaybeDie(err, "unable to initialize kafka client: %v", err)
 			defer cl.Close()
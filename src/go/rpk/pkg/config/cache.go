@@ -0,0 +1,78 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+type (
+	rpkYamlCacheKey struct {
+		fs   afero.Fs
+		path string
+	}
+	rpkYamlCacheEntry struct {
+		modTime time.Time
+		size    int64
+		y       RpkYaml
+	}
+)
+
+var rpkYamlCache struct {
+	mu      sync.Mutex
+	entries map[rpkYamlCacheKey]rpkYamlCacheEntry
+}
+
+// LoadRpkYamlCached is LoadRpkYamlOrDefault for programs that load the same
+// rpk.yaml many times in process, such as scripts that run many rpk commands
+// in one binary. Parsed files are cached by filesystem and absolute path, and
+// the cached rpk.yaml is reused as long as the file's modification time and
+// size are unchanged. Every call returns an independent copy that can be
+// modified and written without affecting the cache. A missing file is never
+// cached and returns the default rpk.yaml, like LoadRpkYamlOrDefault.
+func LoadRpkYamlCached(fs afero.Fs, path string) (RpkYaml, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return RpkYaml{}, err
+	}
+	stat, err := fs.Stat(abs)
+	// We can only key the cache by filesystems that are comparable, which
+	// every afero filesystem is in practice.
+	cacheable := err == nil && reflect.TypeOf(fs).Comparable()
+	if !cacheable {
+		y, _, err := LoadRpkYamlOrDefault(fs, abs)
+		return y, err
+	}
+
+	key := rpkYamlCacheKey{fs, abs}
+	rpkYamlCache.mu.Lock()
+	e, ok := rpkYamlCache.entries[key]
+	rpkYamlCache.mu.Unlock()
+	if ok && e.modTime.Equal(stat.ModTime()) && e.size == stat.Size() {
+		return e.y.Clone(), nil
+	}
+
+	y, _, err := LoadRpkYamlOrDefault(fs, abs)
+	if err != nil {
+		return RpkYaml{}, err
+	}
+	rpkYamlCache.mu.Lock()
+	defer rpkYamlCache.mu.Unlock()
+	if rpkYamlCache.entries == nil {
+		rpkYamlCache.entries = make(map[rpkYamlCacheKey]rpkYamlCacheEntry)
+	}
+	rpkYamlCache.entries[key] = rpkYamlCacheEntry{stat.ModTime(), stat.Size(), y.Clone()}
+	return y, nil
+}
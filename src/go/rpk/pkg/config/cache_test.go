@@ -0,0 +1,90 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRpkYamlCached(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	fs := afero.NewMemMapFs()
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	write := func(broker string, mtime time.Time) {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [%s]
+`, currentRpkYAMLVersion, broker)), 0o644))
+		require.NoError(t, fs.Chtimes(path, mtime, mtime))
+	}
+	brokers := func() []string {
+		y, err := LoadRpkYamlCached(fs, path)
+		require.NoError(t, err)
+		return y.Profile("foo").KafkaAPI.Brokers
+	}
+
+	write("aaaa:9092", mtime)
+	require.Equal(t, []string{"aaaa:9092"}, brokers())
+
+	t.Run("hit", func(t *testing.T) {
+		// Same size and modification time: the file is not read
+		// again, which we observe by sneaking in a different broker.
+		write("bbbb:9092", mtime)
+		require.Equal(t, []string{"aaaa:9092"}, brokers())
+	})
+
+	t.Run("miss after modification", func(t *testing.T) {
+		write("bbbb:9092", mtime.Add(time.Second))
+		require.Equal(t, []string{"bbbb:9092"}, brokers())
+
+		// A size change alone also invalidates the cache.
+		write("cccccc:9092", mtime.Add(time.Second))
+		require.Equal(t, []string{"cccccc:9092"}, brokers())
+	})
+
+	t.Run("independent copy", func(t *testing.T) {
+		y, err := LoadRpkYamlCached(fs, path)
+		require.NoError(t, err)
+		y.Profile("foo").KafkaAPI.Brokers[0] = "modified:9092"
+		y.CurrentProfile = "other"
+		require.Equal(t, []string{"cccccc:9092"}, brokers())
+
+		again, err := LoadRpkYamlCached(fs, path)
+		require.NoError(t, err)
+		require.Equal(t, "foo", again.CurrentProfile)
+		require.Equal(t, path, again.FileLocation())
+	})
+
+	t.Run("missing and invalid files", func(t *testing.T) {
+		y, err := LoadRpkYamlCached(fs, "/missing.yaml")
+		require.NoError(t, err)
+		require.Equal(t, DefaultProfileName, y.CurrentProfile)
+
+		require.NoError(t, afero.WriteFile(fs, "/bad.yaml", []byte("version: [\n"), 0o644))
+		_, err = LoadRpkYamlCached(fs, "/bad.yaml")
+		require.Error(t, err)
+	})
+
+	t.Run("keyed by filesystem", func(t *testing.T) {
+		other := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(other, path, []byte(fmt.Sprintf("version: %d\ncurrent_profile: bar\nprofiles:\n    - name: bar\n", currentRpkYAMLVersion)), 0o644))
+		y, err := LoadRpkYamlCached(other, path)
+		require.NoError(t, err)
+		require.Equal(t, "bar", y.CurrentProfile)
+	})
+}
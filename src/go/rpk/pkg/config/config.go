@@ -78,6 +78,10 @@ type Config struct {
 	rpkYamlInitd  bool    // if OrEmpty was returned to initialize a new "actual" file that has not yet been written
 
 	devOverrides DevOverrides
+
+	// noCurrentProfile is set if the rpk.yaml has multiple profiles and
+	// none is selected; see LoadVirtualProfile.
+	noCurrentProfile error
 }
 
 // CheckExitCloudAdmin exits if the profile has FromCloud=true and no
@@ -96,6 +100,13 @@ func CheckExitServerlessAdmin(p *RpkProfile) {
 	}
 }
 
+// CheckExitReadOnly exits if the profile is read only.
+func CheckExitReadOnly(p *RpkProfile) {
+	if p.IsReadOnly() {
+		out.Die("Profile %q is read only, refusing to run a destructive command; you can change this with 'rpk profile set read_only=false'.", p.Name)
+	}
+}
+
 // VirtualRedpandaYaml returns a redpanda.yaml, starting with defaults,
 // then decoding a potential file, then applying env vars and then flags.
 func (c *Config) VirtualRedpandaYaml() *RedpandaYaml {
@@ -177,6 +188,22 @@ func (c *Config) DevOverrides() DevOverrides {
 	return c.devOverrides
 }
 
+// CloudAPIURL returns the URL of the cloud API that rpk talks to: the
+// RPK_CLOUD_URL override if set, otherwise the cloud_url of the current
+// profile's cloud auth, or of the current cloud auth if the profile has none.
+// This is the production URL if none of these are set.
+func (c *Config) CloudAPIURL() string {
+	if u := c.devOverrides.CloudAPIURL; u != "" {
+		return u
+	}
+	y := c.VirtualRpkYaml()
+	a := y.Profile(y.CurrentProfile).VirtualAuth()
+	if a == nil {
+		a = y.CurrentAuth()
+	}
+	return a.CloudAPIURL()
+}
+
 // LoadVirtualRedpandaYaml is a shortcut for p.Load followed by
 // cfg.VirtualRedpandaYaml.
 func (p *Params) LoadVirtualRedpandaYaml(fs afero.Fs) (*RedpandaYaml, error) {
@@ -198,15 +225,51 @@ func (p *Params) LoadActualRedpandaYamlOrDefaults(fs afero.Fs) (*RedpandaYaml, e
 }
 
 // LoadVirtualProfile is a shortcut for p.Load followed by
-// cfg.VirtualProfile.
+// cfg.VirtualProfile. Unlike Load, this returns an error wrapping
+// ErrNoCurrentProfile that names the available profiles if the rpk.yaml has
+// multiple profiles and none is selected, rather than silently using the
+// default profile.
 func (p *Params) LoadVirtualProfile(fs afero.Fs) (*RpkProfile, error) {
 	cfg, err := p.Load(fs)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.noCurrentProfile != nil {
+		return nil, cfg.noCurrentProfile
+	}
 	return cfg.VirtualProfile(), nil
 }
 
+// Overrides are the flag-specified inputs to Materialize.
+type Overrides struct {
+	// Profile, if non-empty, is used instead of the current profile, as
+	// with --profile.
+	Profile string
+	// X are key=value config overrides, as with -X; see ParamsHelp.
+	X []string
+}
+
+// Materialize loads the rpk.yaml at path, or the default rpk.yaml if path is
+// empty, and returns the fully resolved profile that rpk commands use. It is
+// Params.LoadVirtualProfile for programs that do not use rpk's flags.
+//
+// Settings are layered with the following precedence, highest first:
+//
+//   - flags: the X overrides, which are applied in order
+//   - env: RPK_* variables (see ParamsHelp) and the older REDPANDA_*
+//     variables
+//   - file: the selected profile, after resolving includes and parents
+//   - defaults: values from a redpanda.yaml, if any, then rpk's built-in
+//     defaults
+func Materialize(fs afero.Fs, path string, flags Overrides) (*RpkProfile, error) {
+	p := &Params{
+		ConfigFlag:    path,
+		Profile:       flags.Profile,
+		FlagOverrides: flags.X,
+	}
+	return p.LoadVirtualProfile(fs)
+}
+
 ///////////
 // MODES //
 ///////////
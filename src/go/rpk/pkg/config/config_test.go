@@ -10,7 +10,15 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/utils"
 	"github.com/spf13/afero"
@@ -1017,3 +1025,70 @@ func TestCheckConfig(t *testing.T) {
 		})
 	}
 }
+
+// writeTestCert writes a self signed cert and its key to certPath and keyPath.
+func writeTestCert(t *testing.T, fs afero.Fs, certPath, keyPath string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rpk-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	require.NoError(t, afero.WriteFile(fs, keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func TestTLSConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestCert(t, fs, "/certs/ca.pem", "/certs/ca.key")
+	writeTestCert(t, fs, "/certs/client.pem", "/certs/client.key")
+
+	t.Run("nil", func(t *testing.T) {
+		var tls *TLS
+		tc, err := tls.Config(fs)
+		require.NoError(t, err)
+		require.Nil(t, tc)
+	})
+
+	t.Run("ca only", func(t *testing.T) {
+		tc, err := (&TLS{TruststoreFile: "/certs/ca.pem", ServerName: "broker.local"}).Config(fs)
+		require.NoError(t, err)
+		require.NotNil(t, tc.RootCAs)
+		require.Empty(t, tc.Certificates)
+		require.Equal(t, "broker.local", tc.ServerName)
+		require.False(t, tc.InsecureSkipVerify)
+	})
+
+	t.Run("mtls", func(t *testing.T) {
+		tc, err := (&TLS{
+			TruststoreFile: "/certs/ca.pem",
+			CertFile:       "/certs/client.pem",
+			KeyFile:        "/certs/client.key",
+		}).Config(fs)
+		require.NoError(t, err)
+		require.NotNil(t, tc.RootCAs)
+		require.Len(t, tc.Certificates, 1)
+		require.Equal(t, "", tc.ServerName)
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		tc, err := (&TLS{InsecureSkipVerify: true}).Config(fs)
+		require.NoError(t, err)
+		require.Nil(t, tc.RootCAs)
+		require.True(t, tc.InsecureSkipVerify)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := (&TLS{TruststoreFile: "/certs/missing.pem"}).Config(fs)
+		require.Error(t, err)
+	})
+}
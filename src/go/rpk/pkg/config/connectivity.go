@@ -0,0 +1,102 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	rpknet "github.com/redpanda-data/redpanda/src/go/rpk/pkg/net"
+)
+
+// DefaultCheckDialTimeout is the dial timeout used in CheckConnectivity if the
+// API being checked has no dial timeout configured.
+const DefaultCheckDialTimeout = 3 * time.Second
+
+// Names of the APIs in an EndpointResult.
+const (
+	EndpointKafka          = "kafka"
+	EndpointAdmin          = "admin"
+	EndpointSchemaRegistry = "schema_registry"
+)
+
+// EndpointResult is the result of checking connectivity to one endpoint of a
+// profile.
+type EndpointResult struct {
+	// API is one of EndpointKafka, EndpointAdmin, or EndpointSchemaRegistry.
+	API string
+	// Address is the host:port that was dialed.
+	Address string
+	// Reachable is whether the address accepted a TCP connection.
+	Reachable bool
+	// Err is why the address was not reachable.
+	Err error
+}
+
+// CheckConnectivity dials every Kafka broker, admin API address, and schema
+// registry address of the profile and reports whether each accepted a TCP
+// connection. No TLS handshake or authentication is attempted. Addresses are
+// dialed concurrently with the API's dial timeout, or DefaultCheckDialTimeout,
+// and results are returned in config order: brokers, then admin addresses,
+// then schema registry addresses.
+func (p *RpkProfile) CheckConnectivity(ctx context.Context) []EndpointResult {
+	type endpoint struct {
+		api     string
+		addr    string
+		port    int
+		timeout time.Duration
+	}
+	var endpoints []endpoint
+	for _, a := range p.KafkaAPI.Brokers {
+		endpoints = append(endpoints, endpoint{EndpointKafka, a, DefaultKafkaPort, p.KafkaAPI.DialTimeout.Duration})
+	}
+	for _, a := range p.AdminAPI.Addresses {
+		endpoints = append(endpoints, endpoint{EndpointAdmin, a, DefaultAdminPort, p.AdminAPI.DialTimeout.Duration})
+	}
+	for _, a := range p.SR.Addresses {
+		endpoints = append(endpoints, endpoint{EndpointSchemaRegistry, a, DefaultSchemaRegPort, 0})
+	}
+
+	results := make([]EndpointResult, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		results[i] = EndpointResult{API: e.api, Address: e.addr}
+		_, host, port, err := rpknet.SplitSchemeHostPort(e.addr)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if port == "" {
+			port = strconv.Itoa(e.port)
+		}
+		results[i].Address = net.JoinHostPort(host, port)
+		timeout := e.timeout
+		if timeout == 0 {
+			timeout = DefaultCheckDialTimeout
+		}
+		wg.Add(1)
+		go func(r *EndpointResult) {
+			defer wg.Done()
+			dialer := net.Dialer{Timeout: timeout}
+			conn, err := dialer.DialContext(ctx, "tcp", r.Address)
+			if err != nil {
+				r.Err = err
+				return
+			}
+			conn.Close()
+			r.Reachable = true
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkProfileCheckConnectivity(t *testing.T) {
+	accepting, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer accepting.Close()
+	go func() {
+		for {
+			conn, err := accepting.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	// Grab a free port and close the listener, so that dialing it is refused.
+	refusing, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	refusingAddr := refusing.Addr().String()
+	require.NoError(t, refusing.Close())
+
+	acceptingAddr := accepting.Addr().String()
+	p := RpkProfile{
+		KafkaAPI: RpkKafkaAPI{
+			Brokers:     []string{acceptingAddr, refusingAddr},
+			DialTimeout: Duration{time.Second},
+		},
+		AdminAPI: RpkAdminAPI{
+			Addresses: []string{"http://" + acceptingAddr},
+		},
+		SR: RpkSchemaRegistryAPI{
+			Addresses: []string{"not a valid:address:"},
+		},
+	}
+	results := p.CheckConnectivity(context.Background())
+	require.Len(t, results, 4)
+
+	require.Equal(t, EndpointResult{API: EndpointKafka, Address: acceptingAddr, Reachable: true}, results[0])
+
+	require.Equal(t, EndpointKafka, results[1].API)
+	require.Equal(t, refusingAddr, results[1].Address)
+	require.False(t, results[1].Reachable)
+	require.Error(t, results[1].Err)
+
+	// The scheme is stripped before dialing.
+	require.Equal(t, EndpointResult{API: EndpointAdmin, Address: acceptingAddr, Reachable: true}, results[2])
+
+	require.Equal(t, EndpointSchemaRegistry, results[3].API)
+	require.False(t, results[3].Reachable)
+	require.Error(t, results[3].Err)
+}
+
+func TestRpkProfileCheckConnectivityDefaultPorts(t *testing.T) {
+	p := RpkProfile{
+		KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1"}},
+		AdminAPI: RpkAdminAPI{Addresses: []string{"https://127.0.0.1"}},
+		SR:       RpkSchemaRegistryAPI{Addresses: []string{"127.0.0.1"}},
+	}
+	// A canceled context fails every dial without touching the network.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var addrs []string
+	for _, r := range p.CheckConnectivity(ctx) {
+		require.False(t, r.Reachable)
+		addrs = append(addrs, r.Address)
+	}
+	require.Equal(t, []string{"127.0.0.1:9092", "127.0.0.1:9644", "127.0.0.1:8081"}, addrs)
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change kinds in a ConfigChange.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// ConfigChange is a single difference between two rpk.yaml files, as returned
+// from RpkYaml.Diff.
+type ConfigChange struct {
+	// Section is "profile" or "cloud_auth" for changes to a profile or a
+	// cloud auth, and empty for changes to top level fields.
+	Section string
+	// Name is the name of the changed profile or cloud auth.
+	Name string
+	// Kind is one of ChangeAdded, ChangeRemoved, or ChangeModified.
+	Kind string
+	// Field is the yaml path of a modified field, relative to the profile
+	// or cloud auth for changes within a section. This is empty if an
+	// entire profile or cloud auth was added or removed.
+	Field string
+	// Old and New are the json encoded old and new values of a modified
+	// field; secrets are never included and are "(REDACTED)" instead.
+	Old, New string
+}
+
+func (c ConfigChange) String() string {
+	what := c.Field
+	if c.Section != "" {
+		what = fmt.Sprintf("%s %q", c.Section, c.Name)
+		if c.Field != "" {
+			what += " " + c.Field
+		}
+	}
+	if c.Kind != ChangeModified {
+		return fmt.Sprintf("%s %s", what, c.Kind)
+	}
+	return fmt.Sprintf("%s modified: %s => %s", what, c.Old, c.New)
+}
+
+// Diff returns the changes to go from y to other: added, removed, and modified
+// profiles and cloud auths with field level detail, as well as modified top
+// level fields. Profiles and cloud auths are matched by name. Secret values
+// are never included in the returned changes.
+func (y *RpkYaml) Diff(other RpkYaml) []ConfigChange {
+	var changes []ConfigChange
+
+	top := func(y *RpkYaml) RpkYaml {
+		return RpkYaml{
+			Globals:               y.Globals,
+			CurrentProfile:        y.CurrentProfile,
+			CurrentCloudAuthOrgID: y.CurrentCloudAuthOrgID,
+			CurrentCloudAuthKind:  y.CurrentCloudAuthKind,
+			Includes:              y.Includes,
+			CloudDefaults:         y.CloudDefaults,
+			Extra:                 y.Extra,
+		}
+	}
+	// Secrets are redacted based on eachSecret, such that every secret
+	// field, including per-broker SASL overrides, is covered. A field
+	// is secret if it is a secret in either file.
+	secrets := make(map[[3]string]bool)
+	for _, y := range []*RpkYaml{y, &other} {
+		y.eachSecret(func(ref SecretRef, _ *string) {
+			section, name := "profile", ref.Profile
+			if ref.Auth != "" {
+				section, name = "cloud_auth", ref.Auth
+			}
+			secrets[[3]string{section, name, ref.field()}] = true
+		})
+	}
+	fieldChanges := func(section, name string, l, r any) []ConfigChange {
+		return diffFields(l, r, section, name, func(field string) bool {
+			return secrets[[3]string{section, name, field}]
+		})
+	}
+
+	changes = append(changes, fieldChanges("", "", top(y), top(&other))...)
+
+	diffSection := func(section string, oldNames, newNames []string, get func(y *RpkYaml, name string) any) {
+		isOld := make(map[string]bool)
+		for _, name := range oldNames {
+			isOld[name] = true
+		}
+		isNew := make(map[string]bool)
+		for _, name := range newNames {
+			isNew[name] = true
+		}
+		for _, name := range oldNames {
+			if !isNew[name] {
+				changes = append(changes, ConfigChange{Section: section, Name: name, Kind: ChangeRemoved})
+				continue
+			}
+			changes = append(changes, fieldChanges(section, name, get(y, name), get(&other, name))...)
+		}
+		for _, name := range newNames {
+			if !isOld[name] {
+				changes = append(changes, ConfigChange{Section: section, Name: name, Kind: ChangeAdded})
+			}
+		}
+	}
+	profileNames := func(y *RpkYaml) (names []string) {
+		for _, p := range y.Profiles {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	authNames := func(y *RpkYaml) (names []string) {
+		for _, a := range y.CloudAuths {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+	diffSection("profile", profileNames(y), profileNames(&other), func(y *RpkYaml, name string) any {
+		return y.Profile(name)
+	})
+	diffSection("cloud_auth", authNames(y), authNames(&other), func(y *RpkYaml, name string) any {
+		for i := range y.CloudAuths {
+			if y.CloudAuths[i].Name == name {
+				return &y.CloudAuths[i]
+			}
+		}
+		return nil
+	})
+	return changes
+}
+
+// diffFields returns the modified fields between the yaml encodings of l and
+// r, sorted by field path. The values of fields for which isSecret returns
+// true are redacted.
+func diffFields(l, r any, section, name string, isSecret func(field string) bool) []ConfigChange {
+	lf, rf := make(map[string]string), make(map[string]string)
+	flattenYaml(l, lf)
+	flattenYaml(r, rf)
+
+	fields := make(map[string]struct{})
+	for f := range lf {
+		fields[f] = struct{}{}
+	}
+	for f := range rf {
+		fields[f] = struct{}{}
+	}
+	sorted := make([]string, 0, len(fields))
+	for f := range fields {
+		sorted = append(sorted, f)
+	}
+	sort.Strings(sorted)
+
+	var changes []ConfigChange
+	for _, f := range sorted {
+		ov, oldOK := lf[f]
+		nv, newOK := rf[f]
+		if oldOK == newOK && ov == nv {
+			continue
+		}
+		if isSecret(f) {
+			ov, nv = "(REDACTED)", "(REDACTED)"
+		}
+		if !oldOK {
+			ov = "null"
+		}
+		if !newOK {
+			nv = "null"
+		}
+		changes = append(changes, ConfigChange{
+			Section: section,
+			Name:    name,
+			Kind:    ChangeModified,
+			Field:   f,
+			Old:     ov,
+			New:     nv,
+		})
+	}
+	return changes
+}
+
+// flattenYaml encodes v as yaml and flattens the result into dotted field
+// paths and json encoded leaf values.
+func flattenYaml(v any, into map[string]string) {
+	raw, err := yaml.Marshal(v)
+	if err != nil {
+		return
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return
+	}
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			if sub, ok := v.(map[string]any); ok {
+				walk(k, sub)
+				continue
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				b = []byte(fmt.Sprint(v))
+			}
+			into[k] = string(b)
+		}
+	}
+	walk("", m)
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkYamlDiff(t *testing.T) {
+	base := func() RpkYaml {
+		return RpkYaml{
+			Version:        currentRpkYAMLVersion,
+			CurrentProfile: "foo",
+			Profiles: []RpkProfile{{
+				Name: "foo",
+				KafkaAPI: RpkKafkaAPI{
+					Brokers: []string{"127.0.0.1:9092"},
+					SASL:    &SASL{User: "user", Password: "hunter2", Mechanism: "SCRAM-SHA-256"},
+					BrokerSASL: map[string]*SASL{
+						"h:9092": {User: "other", Password: "old-secret"},
+					},
+				},
+			}},
+			CloudAuths: []RpkCloudAuth{
+				{Name: "a", OrgID: "org-a", Kind: CloudAuthClientCredentials, ClientSecret: "s"},
+				{Name: "b", OrgID: "org-b", Kind: CloudAuthClientCredentials},
+			},
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		modify func(y *RpkYaml)
+		exp    []ConfigChange
+	}{
+		{
+			name:   "no changes",
+			modify: func(*RpkYaml) {},
+		},
+		{
+			name: "added profile",
+			modify: func(y *RpkYaml) {
+				y.Profiles = append(y.Profiles, RpkProfile{Name: "bar"})
+			},
+			exp: []ConfigChange{
+				{Section: "profile", Name: "bar", Kind: ChangeAdded},
+			},
+		},
+		{
+			name: "removed auth",
+			modify: func(y *RpkYaml) {
+				y.CloudAuths = y.CloudAuths[:1]
+			},
+			exp: []ConfigChange{
+				{Section: "cloud_auth", Name: "b", Kind: ChangeRemoved},
+			},
+		},
+		{
+			name: "modified broker list",
+			modify: func(y *RpkYaml) {
+				y.Profiles[0].KafkaAPI.Brokers = []string{"127.0.0.1:9092", "127.0.0.1:9093"}
+			},
+			exp: []ConfigChange{{
+				Section: "profile",
+				Name:    "foo",
+				Kind:    ChangeModified,
+				Field:   "kafka_api.brokers",
+				Old:     `["127.0.0.1:9092"]`,
+				New:     `["127.0.0.1:9092","127.0.0.1:9093"]`,
+			}},
+		},
+		{
+			name: "secrets are redacted",
+			modify: func(y *RpkYaml) {
+				y.Profiles[0].KafkaAPI.SASL.Password = "hunter3"
+				y.CloudAuths[0].ClientSecret = ""
+			},
+			exp: []ConfigChange{
+				{Section: "profile", Name: "foo", Kind: ChangeModified, Field: "kafka_api.sasl.password", Old: "(REDACTED)", New: "(REDACTED)"},
+				{Section: "cloud_auth", Name: "a", Kind: ChangeModified, Field: "client_secret", Old: "(REDACTED)", New: "null"},
+			},
+		},
+		{
+			name: "broker sasl secrets are redacted",
+			modify: func(y *RpkYaml) {
+				y.Profiles[0].KafkaAPI.BrokerSASL["h:9092"].Password = "new-secret"
+				y.Profiles[0].KafkaAPI.BrokerSASL["10.0.0.1:9092"] = &SASL{User: "third", GSSAPI: &SASLGSSAPI{Username: "third", Password: "gssapi-secret"}}
+			},
+			exp: []ConfigChange{
+				{Section: "profile", Name: "foo", Kind: ChangeModified, Field: "kafka_api.broker_sasl.10.0.0.1:9092.gssapi.password", Old: "null", New: "(REDACTED)"},
+				{Section: "profile", Name: "foo", Kind: ChangeModified, Field: "kafka_api.broker_sasl.10.0.0.1:9092.gssapi.username", Old: "null", New: `"third"`},
+				{Section: "profile", Name: "foo", Kind: ChangeModified, Field: "kafka_api.broker_sasl.10.0.0.1:9092.user", Old: "null", New: `"third"`},
+				{Section: "profile", Name: "foo", Kind: ChangeModified, Field: "kafka_api.broker_sasl.h:9092.password", Old: "(REDACTED)", New: "(REDACTED)"},
+			},
+		},
+		{
+			name: "modified top level field",
+			modify: func(y *RpkYaml) {
+				y.CurrentProfile = "bar"
+			},
+			exp: []ConfigChange{
+				{Kind: ChangeModified, Field: "current_profile", Old: `"foo"`, New: `"bar"`},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			y := base()
+			other := base()
+			test.modify(&other)
+			require.Equal(t, test.exp, y.Diff(other))
+		})
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/exp/maps"
+)
+
+// EnvConfigKey is the environment variable that holds the passphrase used to
+// decrypt the secrets of an rpk.yaml written with WriteEncrypted.
+const EnvConfigKey = "RPK_CONFIG_KEY"
+
+// ErrDecryptSecret is returned when an encrypted secret cannot be decrypted,
+// which is almost always because the key is wrong.
+var ErrDecryptSecret = errors.New("unable to decrypt secret, is the key correct?")
+
+// An encrypted secret is encryptedPrefix followed by the base64 encoding of a
+// random scrypt salt, the AES-GCM nonce, and the sealed secret.
+const (
+	encryptedPrefix   = "encrypted:v1:"
+	encryptedSaltSize = 16
+)
+
+// WriteEncrypted writes the rpk.yaml like Write, but with every secret field
+// (SASL passwords, and cloud auth tokens and client secrets) encrypted with a
+// key derived from the given passphrase. Secrets that are already encrypted
+// are written as is. The secrets in y are not modified, but y remembers the
+// key: every later write of y, such as after a token refresh, encrypts any new
+// secrets as well. The file is always written, even if unchanged.
+func (y *RpkYaml) WriteEncrypted(fs afero.Fs, key []byte) error {
+	if len(key) == 0 {
+		return errors.New("unable to encrypt secrets: empty key")
+	}
+	y.encryptKey = append([]byte(nil), key...)
+	location, err := y.writeLocation()
+	if err != nil {
+		return err
+	}
+	return y.WriteAt(fs, location)
+}
+
+// IsEncrypted returns whether secrets are encrypted when y is written, which
+// is the case if y was loaded from a file with encrypted secrets or was
+// written with WriteEncrypted.
+func (y *RpkYaml) IsEncrypted() bool {
+	return len(y.encryptKey) > 0
+}
+
+// forWrite returns what should be written for y: y itself, or if y is
+// encrypted and has secrets that are not, a copy with every secret encrypted.
+func (y *RpkYaml) forWrite() (*RpkYaml, error) {
+	if !y.IsEncrypted() || !y.hasPlaintextSecrets() {
+		return y, nil
+	}
+	dup := y.Clone()
+	if err := dup.EncryptSecrets(y.encryptKey); err != nil {
+		return nil, err
+	}
+	return &dup, nil
+}
+
+// EncryptSecrets encrypts every secret field in place with a key derived from
+// the given passphrase. Empty and already encrypted secrets are left alone.
+func (y *RpkYaml) EncryptSecrets(key []byte) error {
+	if len(key) == 0 {
+		return errors.New("unable to encrypt secrets: empty key")
+	}
+	if !y.hasPlaintextSecrets() {
+		return nil
+	}
+	// Every secret encrypted at once shares a salt, so that the key is
+	// derived once rather than once per secret.
+	ciphers := newSecretCiphers(key)
+	salt := make([]byte, encryptedSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("unable to generate salt: %v", err)
+	}
+	for _, s := range y.secretFields() {
+		if *s == "" || isEncryptedSecret(*s) {
+			continue
+		}
+		enc, err := ciphers.encrypt(salt, *s)
+		if err != nil {
+			return err
+		}
+		*s = enc
+	}
+	return nil
+}
+
+// DecryptSecrets decrypts every encrypted secret field in place. Secrets that
+// are not encrypted are left alone, so this is safe to call on any rpk.yaml.
+// If any secret fails to decrypt, y is not modified and the returned error
+// wraps ErrDecryptSecret.
+func (y *RpkYaml) DecryptSecrets(key []byte) error {
+	fields := y.secretFields()
+	decrypted := make([]string, len(fields))
+	ciphers := newSecretCiphers(key)
+	for i, s := range fields {
+		decrypted[i] = *s
+		if !isEncryptedSecret(*s) {
+			continue
+		}
+		dec, err := ciphers.decrypt(*s)
+		if err != nil {
+			return err
+		}
+		decrypted[i] = dec
+	}
+	for i, s := range fields {
+		*s = decrypted[i]
+	}
+	return nil
+}
+
+// HasEncryptedSecrets returns whether any secret field is encrypted.
+func (y *RpkYaml) HasEncryptedSecrets() bool {
+	for _, s := range y.secretFields() {
+		if isEncryptedSecret(*s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPlaintextSecrets returns whether any secret field is set and not
+// encrypted.
+func (y *RpkYaml) hasPlaintextSecrets() bool {
+	for _, s := range y.secretFields() {
+		if *s != "" && !isEncryptedSecret(*s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecrets returns whether any secret field in the rpk.yaml is non-empty.
+func (y *RpkYaml) hasSecrets() bool {
+	for _, s := range y.secretFields() {
+		if *s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// The kinds of secrets in an rpk.yaml; see SecretRef.
+const (
+	SecretKindSASLPassword   = "sasl_password"
+	SecretKindGSSAPIPassword = "gssapi_password"
+	SecretKindAuthToken      = "auth_token"
+	SecretKindRefreshToken   = "refresh_token"
+	SecretKindClientSecret   = "client_secret"
+)
+
+// SecretRef describes a secret field in an rpk.yaml without its value. Exactly
+// one of Profile and Auth is set, depending on where the secret lives. Broker
+// is set for secrets in a profile's per-broker SASL overrides.
+type SecretRef struct {
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Broker  string `json:"broker,omitempty" yaml:"broker,omitempty"`
+	Auth    string `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Present bool   `json:"present" yaml:"present"`
+}
+
+// field returns the yaml path of the secret, relative to its profile or cloud
+// auth.
+func (r SecretRef) field() string {
+	switch r.Kind {
+	case SecretKindSASLPassword, SecretKindGSSAPIPassword:
+		sasl := "kafka_api.sasl"
+		if r.Broker != "" {
+			sasl = "kafka_api.broker_sasl." + r.Broker
+		}
+		if r.Kind == SecretKindGSSAPIPassword {
+			return sasl + ".gssapi.password"
+		}
+		return sasl + ".password"
+	default:
+		return r.Kind // cloud auth secrets are named by their yaml key
+	}
+}
+
+// SecretRefs returns every secret field in the rpk.yaml, in file order, and
+// whether each is set. Profiles without SASL have no secret fields, while
+// every cloud auth has an auth token, refresh token, and client secret.
+// Per-broker SASL overrides follow the profile's SASL, sorted by broker.
+// Secret values are never included.
+func (y *RpkYaml) SecretRefs() []SecretRef {
+	var refs []SecretRef
+	y.eachSecret(func(ref SecretRef, s *string) {
+		ref.Present = *s != ""
+		refs = append(refs, ref)
+	})
+	return refs
+}
+
+func (y *RpkYaml) secretFields() []*string {
+	var fields []*string
+	y.eachSecret(func(_ SecretRef, s *string) {
+		fields = append(fields, s)
+	})
+	return fields
+}
+
+func (y *RpkYaml) eachSecret(fn func(SecretRef, *string)) {
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		saslSecrets := func(broker string, sasl *SASL) {
+			if sasl == nil {
+				return
+			}
+			fn(SecretRef{Profile: p.Name, Broker: broker, Kind: SecretKindSASLPassword}, &sasl.Password)
+			if sasl.GSSAPI != nil {
+				fn(SecretRef{Profile: p.Name, Broker: broker, Kind: SecretKindGSSAPIPassword}, &sasl.GSSAPI.Password)
+			}
+		}
+		saslSecrets("", p.KafkaAPI.SASL)
+		brokers := maps.Keys(p.KafkaAPI.BrokerSASL)
+		sort.Strings(brokers)
+		for _, broker := range brokers {
+			saslSecrets(broker, p.KafkaAPI.BrokerSASL[broker])
+		}
+	}
+	for i := range y.CloudAuths {
+		a := &y.CloudAuths[i]
+		fn(SecretRef{Auth: a.Name, Kind: SecretKindAuthToken}, &a.AuthToken)
+		fn(SecretRef{Auth: a.Name, Kind: SecretKindRefreshToken}, &a.RefreshToken)
+		fn(SecretRef{Auth: a.Name, Kind: SecretKindClientSecret}, &a.ClientSecret)
+	}
+}
+
+func isEncryptedSecret(s string) bool {
+	return strings.HasPrefix(s, encryptedPrefix)
+}
+
+// secretCiphers derives ciphers from a passphrase, caching them by salt.
+// scrypt is deliberately slow, so a cipher is derived once per salt for an
+// entire rpk.yaml rather than once per secret.
+type secretCiphers struct {
+	key    []byte
+	bySalt map[string]cipher.AEAD
+}
+
+func newSecretCiphers(key []byte) *secretCiphers {
+	return &secretCiphers{key: key, bySalt: make(map[string]cipher.AEAD)}
+}
+
+func (c *secretCiphers) cipher(salt []byte) (cipher.AEAD, error) {
+	if gcm, ok := c.bySalt[string(salt)]; ok {
+		return gcm, nil
+	}
+	derived, err := scrypt.Key(c.key, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive encryption key: %v", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	c.bySalt[string(salt)] = gcm
+	return gcm, nil
+}
+
+func (c *secretCiphers) encrypt(salt []byte, secret string) (string, error) {
+	gcm, err := c.cipher(salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("unable to generate nonce: %v", err)
+	}
+	sealed := append(append([]byte(nil), salt...), nonce...)
+	sealed = gcm.Seal(sealed, nonce, []byte(secret), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *secretCiphers) decrypt(secret string) (string, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(secret, encryptedPrefix))
+	if err != nil || len(raw) < encryptedSaltSize {
+		return "", fmt.Errorf("%w: malformed encrypted secret", ErrDecryptSecret)
+	}
+	gcm, err := c.cipher(raw[:encryptedSaltSize])
+	if err != nil {
+		return "", err
+	}
+	raw = raw[encryptedSaltSize:]
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: malformed encrypted secret", ErrDecryptSecret)
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrDecryptSecret
+	}
+	return string(plain), nil
+}
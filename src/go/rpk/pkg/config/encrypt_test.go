@@ -0,0 +1,204 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkYamlWriteEncrypted(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	key := []byte("correct horse battery staple")
+
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:     "foo",
+			KafkaAPI: RpkKafkaAPI{SASL: &SASL{User: "user", Password: "hunter2", Mechanism: "SCRAM-SHA-256"}},
+		}},
+		CloudAuths: []RpkCloudAuth{{
+			Name:         "auth",
+			Organization: "org",
+			OrgID:        "org",
+			Kind:         CloudAuthClientCredentials,
+			AuthToken:    "token",
+			ClientID:     "id",
+			ClientSecret: "secret",
+		}},
+	}
+	fs := afero.NewMemMapFs()
+	y.fileLocation = path
+	require.NoError(t, y.WriteEncrypted(fs, key))
+	require.Equal(t, "hunter2", y.Profiles[0].KafkaAPI.SASL.Password, "WriteEncrypted modified its receiver")
+
+	raw, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "token", "secret"} {
+		require.NotContains(t, string(raw), secret+"\n")
+	}
+	require.Equal(t, 3, strings.Count(string(raw), encryptedPrefix))
+
+	t.Run("round trip", func(t *testing.T) {
+		cfg, err := (&Params{ConfigFlag: path, ConfigKey: key}).Load(fs)
+		require.NoError(t, err)
+		p := cfg.VirtualProfile()
+		require.Equal(t, "hunter2", p.KafkaAPI.SASL.Password)
+		a := cfg.VirtualRpkYaml().LookupAuth("org", CloudAuthClientCredentials)
+		require.Equal(t, "token", a.AuthToken)
+		require.Equal(t, "secret", a.ClientSecret)
+
+		// The actual rpk.yaml keeps the secrets encrypted.
+		act, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		require.True(t, act.HasEncryptedSecrets())
+	})
+
+	t.Run("secrets share a salt", func(t *testing.T) {
+		var salts []string
+		for _, line := range strings.Split(string(raw), "\n") {
+			_, enc, ok := strings.Cut(line, encryptedPrefix)
+			if !ok {
+				continue
+			}
+			sealed, err := base64.RawStdEncoding.DecodeString(enc)
+			require.NoError(t, err)
+			salts = append(salts, string(sealed[:encryptedSaltSize]))
+		}
+		require.Len(t, salts, 3)
+		require.Equal(t, salts[0], salts[1])
+		require.Equal(t, salts[0], salts[2])
+	})
+
+	t.Run("later writes stay encrypted", func(t *testing.T) {
+		cfg, err := (&Params{ConfigFlag: path, ConfigKey: key}).Load(fs)
+		require.NoError(t, err)
+		act, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		require.True(t, act.IsEncrypted())
+
+		// As on a token refresh, a new secret is written in place
+		// of an encrypted one.
+		act.LookupAuth("org", CloudAuthClientCredentials).AuthToken = "new-token"
+		require.NoError(t, act.Write(fs))
+		require.Equal(t, "new-token", act.LookupAuth("org", CloudAuthClientCredentials).AuthToken)
+
+		raw, err := afero.ReadFile(fs, path)
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "new-token")
+		require.Equal(t, 3, strings.Count(string(raw), encryptedPrefix))
+
+		cfg, err = (&Params{ConfigFlag: path, ConfigKey: key}).Load(fs)
+		require.NoError(t, err)
+		require.Equal(t, "new-token", cfg.VirtualRpkYaml().LookupAuth("org", CloudAuthClientCredentials).AuthToken)
+		require.Equal(t, "hunter2", cfg.VirtualProfile().KafkaAPI.SASL.Password)
+	})
+
+	t.Run("stdout stays encrypted", func(t *testing.T) {
+		cfg, err := (&Params{ConfigFlag: path, ConfigKey: key}).Load(fs)
+		require.NoError(t, err)
+		act, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		act.Profile("foo").KafkaAPI.SASL.Password = "new-password"
+
+		var buf bytes.Buffer
+		old := stdout
+		stdout = &buf
+		defer func() { stdout = old }()
+		require.NoError(t, act.WriteAt(fs, "-"))
+
+		var to bytes.Buffer
+		_, err = act.WriteTo(&to)
+		require.NoError(t, err)
+
+		js, err := act.ToJSON()
+		require.NoError(t, err)
+
+		for _, out := range []string{buf.String(), to.String(), string(js)} {
+			require.NotContains(t, out, "new-password")
+			require.Equal(t, 3, strings.Count(out, encryptedPrefix))
+		}
+		require.Equal(t, "new-password", act.Profile("foo").KafkaAPI.SASL.Password)
+	})
+
+	t.Run("key from env", func(t *testing.T) {
+		t.Setenv(EnvConfigKey, string(key))
+		cfg, err := (&Params{ConfigFlag: path}).Load(fs)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", cfg.VirtualProfile().KafkaAPI.SASL.Password)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := (&Params{ConfigFlag: path, ConfigKey: []byte("wrong")}).Load(fs)
+		require.True(t, errors.Is(err, ErrDecryptSecret), "got err %v", err)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		t.Setenv(EnvConfigKey, "")
+		_, err := (&Params{ConfigFlag: path}).Load(fs)
+		require.ErrorContains(t, err, EnvConfigKey)
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		plain := afero.NewMemMapFs()
+		unencrypted := y.Clone()
+		unencrypted.encryptKey = nil
+		require.NoError(t, unencrypted.WriteAt(plain, path))
+		raw, err := afero.ReadFile(plain, path)
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), encryptedPrefix)
+		cfg, err := (&Params{ConfigFlag: path, ConfigKey: key}).Load(plain)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", cfg.VirtualProfile().KafkaAPI.SASL.Password)
+	})
+}
+
+func TestRpkYamlSecretRefs(t *testing.T) {
+	y := RpkYaml{
+		Profiles: []RpkProfile{
+			{Name: "plain"},
+			{Name: "scram", KafkaAPI: RpkKafkaAPI{SASL: &SASL{User: "user", Password: "hunter2", Mechanism: "SCRAM-SHA-256"}}},
+			{Name: "kerberos", KafkaAPI: RpkKafkaAPI{SASL: &SASL{Mechanism: "GSSAPI", GSSAPI: &SASLGSSAPI{Keytab: "/rpk.keytab"}}}},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "sso", AuthToken: "tok-value", RefreshToken: "refresh-value"},
+			{Name: "creds", ClientID: "id", ClientSecret: "env:CLIENT_SECRET"},
+		},
+	}
+
+	refs := y.SecretRefs()
+	require.Equal(t, []SecretRef{
+		{Profile: "scram", Kind: SecretKindSASLPassword, Present: true},
+		{Profile: "kerberos", Kind: SecretKindSASLPassword},
+		{Profile: "kerberos", Kind: SecretKindGSSAPIPassword},
+		{Auth: "sso", Kind: SecretKindAuthToken, Present: true},
+		{Auth: "sso", Kind: SecretKindRefreshToken, Present: true},
+		{Auth: "sso", Kind: SecretKindClientSecret},
+		{Auth: "creds", Kind: SecretKindAuthToken},
+		{Auth: "creds", Kind: SecretKindRefreshToken},
+		{Auth: "creds", Kind: SecretKindClientSecret, Present: true},
+	}, refs)
+
+	raw, err := json.Marshal(refs)
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "tok-value", "refresh-value", "CLIENT_SECRET"} {
+		require.NotContains(t, string(raw), secret)
+	}
+
+	require.Empty(t, (&RpkYaml{}).SecretRefs())
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EnvironmentNames returns the names of all environments, sorted.
+func (y *RpkYaml) EnvironmentNames() []string {
+	names := make([]string, 0, len(y.Environments))
+	for env := range y.Environments {
+		names = append(names, env)
+	}
+	sort.Slice(names, func(i, j int) bool { return lessName(names[i], names[j]) })
+	return names
+}
+
+// EnvironmentProfiles returns the existing profiles of the given environment
+// in the order they are listed, or ErrEnvironmentNotFound if the environment
+// does not exist. Listed names that do not match a profile are skipped, and
+// aliases are resolved.
+func (y *RpkYaml) EnvironmentProfiles(env string) ([]*RpkProfile, error) {
+	names, ok := y.Environments[env]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrEnvironmentNotFound, env)
+	}
+	var ps []*RpkProfile
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if p := y.Profile(name); p != nil && !seen[p.Name] {
+			seen[p.Name] = true
+			ps = append(ps, p)
+		}
+	}
+	return ps, nil
+}
+
+// ActiveProfiles returns the profiles of the current environment, or every
+// profile if no environment is selected.
+func (y *RpkYaml) ActiveProfiles() []*RpkProfile {
+	if y.CurrentEnvironment != "" {
+		ps, _ := y.EnvironmentProfiles(y.CurrentEnvironment)
+		return ps
+	}
+	ps := make([]*RpkProfile, 0, len(y.Profiles))
+	for i := range y.Profiles {
+		ps = append(ps, &y.Profiles[i])
+	}
+	return ps
+}
+
+// SetCurrentEnvironment switches to the given environment and returns the
+// current profile afterwards. If the current profile is not part of the
+// environment, the environment's primary profile (its first existing
+// profile) becomes the current profile; if the environment has no profiles,
+// the current profile is left alone and nil is returned. An empty env clears
+// the current environment, making every profile active again. This returns
+// ErrEnvironmentNotFound if the environment does not exist.
+func (y *RpkYaml) SetCurrentEnvironment(env string) (*RpkProfile, error) {
+	if env == "" {
+		y.CurrentEnvironment = ""
+		return y.Profile(y.CurrentProfile), nil
+	}
+	ps, err := y.EnvironmentProfiles(env)
+	if err != nil {
+		return nil, err
+	}
+	y.CurrentEnvironment = env
+	for _, p := range ps {
+		if p.Name == y.CurrentProfile {
+			return p, nil
+		}
+	}
+	if len(ps) == 0 {
+		return nil, nil
+	}
+	return y.SetCurrentProfile(ps[0].Name)
+}
+
+// useEnvironmentProfile selects the current environment's primary profile in
+// the virtual rpk.yaml if the current profile is not in the environment, so
+// that commands default to the environment even if the rpk.yaml was edited
+// by hand.
+func (y *RpkYaml) useEnvironmentProfile() {
+	if y.CurrentEnvironment == "" {
+		return
+	}
+	ps, err := y.EnvironmentProfiles(y.CurrentEnvironment)
+	if err != nil || len(ps) == 0 {
+		return
+	}
+	for _, p := range ps {
+		if p.Name == y.CurrentProfile {
+			return
+		}
+	}
+	y.CurrentProfile = ps[0].Name
+}
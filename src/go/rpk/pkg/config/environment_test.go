@@ -0,0 +1,158 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkYamlEnvironments(t *testing.T) {
+	mk := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile: "dev",
+			Profiles: []RpkProfile{
+				{Name: "dev"},
+				{Name: "stage-east", Aliases: []string{"stage"}},
+				{Name: "stage-west"},
+				{Name: "prod-east"},
+				{Name: "prod-west"},
+			},
+			Environments: map[string][]string{
+				"staging": {"stage", "stage-west", "missing"},
+				"prod":    {"prod-east", "prod-west"},
+				"empty":   nil,
+			},
+		}
+	}
+	names := func(ps []*RpkProfile) []string {
+		var ns []string
+		for _, p := range ps {
+			ns = append(ns, p.Name)
+		}
+		return ns
+	}
+
+	t.Run("list", func(t *testing.T) {
+		y := mk()
+		require.Equal(t, []string{"empty", "prod", "staging"}, y.EnvironmentNames())
+
+		ps, err := y.EnvironmentProfiles("staging")
+		require.NoError(t, err)
+		require.Equal(t, []string{"stage-east", "stage-west"}, names(ps))
+
+		ps, err = y.EnvironmentProfiles("empty")
+		require.NoError(t, err)
+		require.Empty(t, ps)
+
+		_, err = y.EnvironmentProfiles("qa")
+		require.True(t, errors.Is(err, ErrEnvironmentNotFound), "got err %v", err)
+
+		require.Len(t, y.ActiveProfiles(), 5)
+	})
+
+	t.Run("switch", func(t *testing.T) {
+		y := mk()
+
+		// The current profile is not in prod: the primary is selected.
+		p, err := y.SetCurrentEnvironment("prod")
+		require.NoError(t, err)
+		require.Equal(t, "prod-east", p.Name)
+		require.Equal(t, "prod", y.CurrentEnvironment)
+		require.Equal(t, "prod-east", y.CurrentProfile)
+		require.Equal(t, []string{"prod-east", "prod-west"}, names(y.ActiveProfiles()))
+
+		// The current profile is kept if it is in the environment.
+		_, err = y.SetCurrentProfile("prod-west")
+		require.NoError(t, err)
+		_, err = y.SetCurrentEnvironment("staging")
+		require.NoError(t, err)
+		require.Equal(t, "stage-east", y.CurrentProfile)
+		_, err = y.SetCurrentProfile("stage-west")
+		require.NoError(t, err)
+		p, err = y.SetCurrentEnvironment("staging")
+		require.NoError(t, err)
+		require.Equal(t, "stage-west", p.Name)
+
+		// An environment without profiles leaves the profile alone.
+		p, err = y.SetCurrentEnvironment("empty")
+		require.NoError(t, err)
+		require.Nil(t, p)
+		require.Equal(t, "stage-west", y.CurrentProfile)
+		require.Empty(t, y.ActiveProfiles())
+
+		_, err = y.SetCurrentEnvironment("qa")
+		require.True(t, errors.Is(err, ErrEnvironmentNotFound), "got err %v", err)
+		require.Equal(t, "empty", y.CurrentEnvironment)
+
+		p, err = y.SetCurrentEnvironment("")
+		require.NoError(t, err)
+		require.Equal(t, "stage-west", p.Name)
+		require.Empty(t, y.CurrentEnvironment)
+		require.Len(t, y.ActiveProfiles(), 5)
+	})
+
+	t.Run("rename and delete", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.RenameProfile("prod-east", "prod-central"))
+		require.NoError(t, y.DeleteProfile("stage-west"))
+		require.Equal(t, []string{"prod-central", "prod-west"}, y.Environments["prod"])
+		require.Equal(t, []string{"stage", "missing"}, y.Environments["staging"])
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		y := mk()
+		y.CurrentEnvironment = "qa"
+		err := y.Validate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `current environment "qa" does not exist`)
+		require.Contains(t, err.Error(), `environment "staging" profile "missing" does not exist`)
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		y := mk()
+		dup := y.Clone()
+		dup.Environments["prod"][0] = "changed"
+		require.Equal(t, "prod-east", y.Environments["prod"][0])
+	})
+}
+
+func TestLoadCurrentEnvironment(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: dev
+current_environment: prod
+environments:
+    prod: [prod-east, prod-west]
+profiles:
+    - name: dev
+    - name: prod-east
+    - name: prod-west
+`},
+	})
+
+	// The current profile is not in the current environment, so commands
+	// use the environment's primary profile, without changing the file.
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "prod-east", cfg.VirtualProfile().Name)
+	y, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	require.Equal(t, "dev", y.CurrentProfile)
+	require.Equal(t, []string{"prod-east", "prod-west"}, y.Environments["prod"])
+
+	// --profile wins over the environment.
+	cfg, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml", Profile: "dev"}).Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "dev", cfg.VirtualProfile().Name)
+}
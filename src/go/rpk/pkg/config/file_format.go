@@ -0,0 +1,128 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	rpkos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+)
+
+// FileFormat is the encoding of an rpk configuration file.
+type FileFormat string
+
+const (
+	FileFormatYAML FileFormat = "yaml"
+	FileFormatTOML FileFormat = "toml"
+)
+
+// FileFormatFor returns the format of the file at path based on its
+// extension: ".toml" files are TOML, and everything else, including ".yaml"
+// and ".yml", is YAML.
+func FileFormatFor(path string) FileFormat {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return FileFormatTOML
+	}
+	return FileFormatYAML
+}
+
+// LoadAny loads the rpk configuration at path, which is decoded as YAML or
+// TOML based on its extension (see FileFormatFor). Both formats use the same
+// keys as rpk.yaml. Unlike LoadRpkYamlOrDefault, the file must exist.
+//
+// A YAML file is loaded exactly as with LoadRpkYamlOrDefault. A TOML file
+// has no file location, because Write always writes YAML: use WriteAs to
+// write it back.
+func LoadAny(fs afero.Fs, path string) (RpkYaml, error) {
+	abs, file, err := readFile(fs, path)
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	if FileFormatFor(abs) == FileFormatYAML {
+		y, err := parseRpkYaml(file, abs)
+		if err != nil {
+			return RpkYaml{}, err
+		}
+		y.fileLocation = abs
+		y.fileRaw = file
+		return y, nil
+	}
+
+	// We decode TOML through YAML, so that TOML files support exactly what
+	// rpk.yaml supports, including weak types and durations.
+	var m map[string]any
+	if _, err := toml.Decode(string(file), &m); err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to toml decode %s: %v", abs, err)
+	}
+	asYaml, err := yaml.Marshal(m)
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to convert %s to yaml: %v", abs, err)
+	}
+	return parseRpkYaml(asYaml, abs)
+}
+
+// WriteAs writes the configuration to path in the given format. Writing YAML
+// is the same as WriteAt. TOML files contain the same keys as rpk.yaml, with
+// keys sorted within each table.
+func (y *RpkYaml) WriteAs(fs afero.Fs, path string, format FileFormat) error {
+	switch format {
+	case FileFormatYAML:
+		return y.WriteAt(fs, path)
+	case FileFormatTOML:
+	default:
+		return fmt.Errorf("unknown config file format %q", format)
+	}
+
+	w, err := y.forWrite()
+	if err != nil {
+		return err
+	}
+	b, err := w.marshalTOML()
+	if err != nil {
+		return fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	if path == "-" {
+		if _, err := stdout.Write(b); err != nil {
+			return fmt.Errorf("unable to write to stdout: %v", err)
+		}
+		return nil
+	}
+	unlock, err := rpkos.LockExclusive(fs, path+".lock")
+	if err != nil {
+		return fmt.Errorf("unable to lock %s for writing: %v", path, err)
+	}
+	defer unlock()
+	return y.replaceFile(fs, path, b)
+}
+
+// marshalTOML encodes y through YAML, such that the TOML has exactly the
+// structure of the YAML encoding, as with ToJSON.
+func (y *RpkYaml) marshalTOML() ([]byte, error) {
+	b, err := yaml.Marshal(y)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
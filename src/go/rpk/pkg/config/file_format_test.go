@@ -0,0 +1,141 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFormatFor(t *testing.T) {
+	for path, exp := range map[string]FileFormat{
+		"/etc/rpk/rpk.yaml": FileFormatYAML,
+		"rpk.yml":           FileFormatYAML,
+		"rpk.toml":          FileFormatTOML,
+		"RPK.TOML":          FileFormatTOML,
+		"/etc/rpk/config":   FileFormatYAML,
+	} {
+		require.Equal(t, exp, FileFormatFor(path), "path %s", path)
+	}
+}
+
+func TestLoadAnyWriteAsRoundTrip(t *testing.T) {
+	y, err := defaultVirtualRpkYaml()
+	require.NoError(t, err)
+	y.Globals.DialTimeout = Duration{5 * time.Second}
+	y.CurrentProfile = "foo"
+	y.CurrentCloudAuthOrgID = "org-id"
+	y.CurrentCloudAuthKind = CloudAuthClientCredentials
+	y.Profiles = []RpkProfile{{
+		Name:        "foo",
+		Description: "dev cluster",
+		Labels:      map[string]string{"env": "dev"},
+		CreatedAt:   Timestamp{time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		CloudCluster: RpkCloudCluster{
+			ClusterID: "cluster-id",
+			AuthOrgID: "org-id",
+			AuthKind:  CloudAuthClientCredentials,
+		},
+		KafkaAPI: RpkKafkaAPI{
+			Brokers: []string{"10.0.0.1:9092", "10.0.0.2:9092"},
+			TLS:     new(TLS),
+			SASL:    &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"},
+		},
+		AdminAPI: RpkAdminAPI{
+			Addresses:      []string{"10.0.0.1:9644"},
+			RequestTimeout: Duration{30 * time.Second},
+		},
+		ProfileDefaults: RpkProfileDefaults{Partitions: 3},
+		Extra:           map[string]any{"unknown_key": "kept"},
+	}, {
+		Name: "bar",
+	}}
+	y.CloudAuths = []RpkCloudAuth{{
+		Name:         "auth",
+		Organization: "org",
+		OrgID:        "org-id",
+		Kind:         CloudAuthClientCredentials,
+		ClientID:     "id",
+		ClientSecret: "secret",
+	}}
+
+	fs := afero.NewMemMapFs()
+	load := func(path string, format FileFormat) RpkYaml {
+		require.NoError(t, y.WriteAs(fs, path, format))
+		got, err := LoadAny(fs, path)
+		require.NoError(t, err)
+		got.fileLocation, got.fileRaw = "", nil
+		return got
+	}
+
+	exp := y.Clone()
+	exp.Version, exp.fileLocation = currentRpkYAMLVersion, ""
+	fromYaml := load("/etc/rpk/rpk.yaml", FileFormatYAML)
+	require.Equal(t, exp, fromYaml)
+	fromToml := load("/etc/rpk/rpk.toml", FileFormatTOML)
+	require.Equal(t, fromYaml, fromToml)
+
+	raw, err := afero.ReadFile(fs, "/etc/rpk/rpk.toml")
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "[[profiles]]")
+	require.Contains(t, string(raw), `current_profile = "foo"`)
+
+	// Files with secrets are restricted to their owner in both formats.
+	stat, err := fs.Stat("/etc/rpk/rpk.toml")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	t.Run("toml has no file location", func(t *testing.T) {
+		got, err := LoadAny(fs, "/etc/rpk/rpk.toml")
+		require.NoError(t, err)
+		require.Empty(t, got.FileLocation())
+
+		got, err = LoadAny(fs, "/etc/rpk/rpk.yaml")
+		require.NoError(t, err)
+		require.Equal(t, "/etc/rpk/rpk.yaml", got.FileLocation())
+	})
+
+	t.Run("hand written toml", func(t *testing.T) {
+		require.NoError(t, afero.WriteFile(fs, "/hand.toml", []byte(fmt.Sprintf(`version = %d
+current_profile = "foo"
+
+[[profiles]]
+name = "foo"
+
+[profiles.kafka_api]
+brokers = ["127.0.0.1:9092"]
+request_timeout_overhead = "10s"
+`, currentRpkYAMLVersion)), 0o644))
+		got, err := LoadAny(fs, "/hand.toml")
+		require.NoError(t, err)
+		p := got.Profile("foo")
+		require.NotNil(t, p)
+		require.Equal(t, []string{"127.0.0.1:9092"}, p.KafkaAPI.Brokers)
+		require.Equal(t, 10*time.Second, p.KafkaAPI.RequestTimeoutOverhead.Duration)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := LoadAny(fs, "/missing.toml")
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		require.NoError(t, afero.WriteFile(fs, "/bad.toml", []byte("version = [\n"), 0o644))
+		_, err = LoadAny(fs, "/bad.toml")
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), "toml decode"), "err: %v", err)
+
+		require.Error(t, y.WriteAs(fs, "/rpk.json", "json"))
+	})
+}
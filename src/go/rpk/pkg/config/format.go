@@ -135,6 +135,11 @@ func formatType(t any, includeTypeName bool) (string, error) {
 			if jtag == "" {
 				return fmt.Errorf("field %s.%s at %s is missing a json tag", typ.Name(), sf.Name, sb.String())
 			}
+			// encoding/json cannot inline a catch-all map; such maps
+			// are json:"-" and encoded by marshalJSONInline.
+			if jtag == "-" && strings.HasPrefix(ytag, ",inline") && sf.Type.Kind() == reflect.Map {
+				jtag = ytag
+			}
 			if jtag != ytag {
 				return fmt.Errorf("field %s.%s at %s has different json:%q and yaml:%q tags", typ.Name(), sf.Name, sb.String(), jtag, ytag)
 			}
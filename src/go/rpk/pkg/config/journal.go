@@ -0,0 +1,138 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// journalNow is the clock used to timestamp journal entries; tests replace
+// it.
+var journalNow = time.Now
+
+// JournalPath returns the path of the journal for an rpk.yaml at path.
+func JournalPath(path string) string {
+	return path + ".journal"
+}
+
+// readJournalPrior returns the rpk.yaml currently at location, to be compared
+// against what is about to be written. If journaling is disabled, this returns
+// nil, false. A missing or undecodable file is journaled as empty.
+func (y *RpkYaml) readJournalPrior(fs afero.Fs, location string) (*RpkYaml, bool) {
+	if !y.Globals.Journal {
+		return nil, false
+	}
+	var prior RpkYaml
+	if raw, err := afero.ReadFile(fs, location); err == nil {
+		yaml.Unmarshal(raw, &prior) //nolint:errcheck // journaling is best effort
+	}
+	return &prior, true
+}
+
+// appendJournal appends a timestamped line to the journal next to location
+// summarizing the changes from prior to y. The journal has the same mode as
+// the rpk.yaml at location, so that it is no more readable than the file it
+// describes. Journaling is best effort: the rpk.yaml has already been
+// written, so failing to journal is not an error.
+func (y *RpkYaml) appendJournal(fs afero.Fs, location string, prior *RpkYaml) {
+	stat, err := fs.Stat(location)
+	if err != nil {
+		return
+	}
+	perm := stat.Mode().Perm()
+	line := fmt.Sprintf("%s %s\n", journalNow().UTC().Format(time.RFC3339), journalSummary(prior, y))
+	path := JournalPath(location)
+	f, err := fs.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return
+	}
+	fs.Chmod(path, perm) //nolint:errcheck // journaling is best effort
+	defer f.Close()
+	f.WriteString(line) //nolint:errcheck // journaling is best effort
+}
+
+// journalSummary describes what changed from prev to next: profiles and cloud
+// auths added, removed, or updated, and the current profile switched. Only
+// names are journaled, never values, so that the journal contains no secrets.
+func journalSummary(prev, next *RpkYaml) string {
+	var changes []string
+	diff := func(kind string, prev, next []journalItem) {
+		prevItems := make(map[string]string, len(prev))
+		for _, it := range prev {
+			prevItems[it.name] = it.yaml
+		}
+		nextItems := make(map[string]bool, len(next))
+		for _, it := range next {
+			nextItems[it.name] = true
+			prevYaml, exists := prevItems[it.name]
+			switch {
+			case !exists:
+				changes = append(changes, fmt.Sprintf("added %s %q", kind, it.name))
+			case prevYaml != it.yaml:
+				changes = append(changes, fmt.Sprintf("updated %s %q", kind, it.name))
+			}
+		}
+		for _, it := range prev {
+			if !nextItems[it.name] {
+				changes = append(changes, fmt.Sprintf("removed %s %q", kind, it.name))
+			}
+		}
+	}
+	diff("profile", journalProfiles(prev), journalProfiles(next))
+	diff("cloud auth", journalAuths(prev), journalAuths(next))
+	if prev.CurrentProfile != next.CurrentProfile {
+		changes = append(changes, fmt.Sprintf("switched current profile from %q to %q", prev.CurrentProfile, next.CurrentProfile))
+	}
+	if journalYaml(prev.Globals) != journalYaml(next.Globals) {
+		changes = append(changes, "updated globals")
+	}
+	if len(changes) == 0 {
+		return "updated rpk.yaml"
+	}
+	return strings.Join(changes, "; ")
+}
+
+// journalItem is a named profile or cloud auth and its yaml encoding, which is
+// compared to detect updates.
+type journalItem struct {
+	name string
+	yaml string
+}
+
+// journalProfiles ignores last used timestamps, which change whenever a
+// profile is loaded and are not worth journaling.
+func journalProfiles(y *RpkYaml) []journalItem {
+	items := make([]journalItem, 0, len(y.Profiles))
+	for i := range y.Profiles {
+		p := y.Profiles[i]
+		p.LastUsedAt = Timestamp{}
+		items = append(items, journalItem{p.Name, journalYaml(&p)})
+	}
+	return items
+}
+
+func journalAuths(y *RpkYaml) []journalItem {
+	items := make([]journalItem, 0, len(y.CloudAuths))
+	for i := range y.CloudAuths {
+		items = append(items, journalItem{y.CloudAuths[i].Name, journalYaml(&y.CloudAuths[i])})
+	}
+	return items
+}
+
+func journalYaml(v any) string {
+	b, _ := yaml.Marshal(v)
+	return string(b)
+}
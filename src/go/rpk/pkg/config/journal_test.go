@@ -0,0 +1,121 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkYamlWriteJournal(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	now := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)
+	journalNow = func() time.Time { return now }
+	defer func() { journalNow = time.Now }()
+
+	load := func(t *testing.T, fs afero.Fs) *RpkYaml {
+		cfg, err := (&Params{ConfigFlag: path}).Load(fs)
+		require.NoError(t, err)
+		y, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		return y
+	}
+	readJournal := func(t *testing.T, fs afero.Fs) string {
+		b, err := afero.ReadFile(fs, JournalPath(path))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		path: {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+    - name: old
+`},
+	})
+
+	// Enabling the journal is the first journaled write.
+	y := load(t, fs)
+	y.Globals.Journal = true
+	require.NoError(t, y.Write(fs))
+	exp := "2026-10-14T12:30:00Z updated globals\n"
+	require.Equal(t, exp, readJournal(t, fs))
+
+	// An unchanged rpk.yaml is not written, and so is not journaled.
+	y = load(t, fs)
+	require.NoError(t, y.Write(fs))
+	require.Equal(t, exp, readJournal(t, fs))
+
+	y.PushProfile(RpkProfile{
+		Name:     "bar",
+		KafkaAPI: RpkKafkaAPI{SASL: &SASL{User: "user", Password: "hunter2"}},
+	})
+	require.NoError(t, y.DeleteProfile("old"))
+	require.NoError(t, y.Write(fs))
+	exp += `2026-10-14T12:30:00Z added profile "bar"; removed profile "old"; switched current profile from "foo" to "bar"` + "\n"
+	require.Equal(t, exp, readJournal(t, fs))
+	require.NotContains(t, readJournal(t, fs), "hunter2")
+
+	// The journal follows the rpk.yaml, which is now restricted because it
+	// contains a secret.
+	stat, err := fs.Stat(JournalPath(path))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	// Entries are appended.
+	now = now.Add(time.Hour)
+	y = load(t, fs)
+	y.Profile("foo").Description = "updated"
+	require.NoError(t, y.WriteWithBackup(fs))
+	exp += `2026-10-14T13:30:00Z updated profile "foo"` + "\n"
+	require.Equal(t, exp, readJournal(t, fs))
+
+	// Once the journal is disabled, writes are no longer journaled.
+	y = load(t, fs)
+	y.Globals.Journal = false
+	require.NoError(t, y.Write(fs))
+	y = load(t, fs)
+	y.Profile("foo").Description = "again"
+	require.NoError(t, y.Write(fs))
+	require.Equal(t, exp, readJournal(t, fs))
+}
+
+func TestRpkYamlWriteJournalBestEffort(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		path: {Mode: 0o644, Contents: `version: 8
+globals:
+    journal: true
+current_profile: foo
+profiles:
+    - name: foo
+`},
+	})
+	// A directory in place of the journal cannot be appended to, but
+	// the rpk.yaml is still written.
+	require.NoError(t, fs.MkdirAll(JournalPath(path), 0o755))
+
+	cfg, err := (&Params{ConfigFlag: path}).Load(fs)
+	require.NoError(t, err)
+	y, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	y.Profile("foo").Description = "updated"
+	require.NoError(t, y.Write(fs))
+
+	cfg, err = (&Params{ConfigFlag: path}).Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "updated", cfg.VirtualProfile().Description)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// ErrInvalidToken is returned from RpkCloudAuth.Verify if the auth token
+// signature is invalid, or if the token is malformed or expired.
+var ErrInvalidToken = errors.New("invalid cloud auth token")
+
+// JWKSCache fetches and caches JSON Web Key Sets for verifying cloud auth
+// tokens. A JWKSCache is safe for concurrent use.
+type JWKSCache struct {
+	client *http.Client
+	ttl    time.Duration
+
+	mu   sync.Mutex
+	sets map[string]cachedJWKS
+}
+
+type cachedJWKS struct {
+	set     jwk.Set
+	fetched time.Time
+}
+
+// NewJWKSCache returns a JWKSCache that fetches key sets with client and
+// refetches a key set once it is older than ttl. If client is nil, a default
+// client with a 15s timeout is used.
+func NewJWKSCache(client *http.Client, ttl time.Duration) *JWKSCache {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &JWKSCache{
+		client: client,
+		ttl:    ttl,
+		sets:   make(map[string]cachedJWKS),
+	}
+}
+
+var defaultJWKSCache = NewJWKSCache(nil, time.Hour)
+
+// Fetch returns the key set at jwksURL, fetching it if it is not cached or if
+// the cached key set has expired. The cache is not locked while fetching, so a
+// slow key set endpoint does not block lookups of other key sets.
+func (c *JWKSCache) Fetch(ctx context.Context, jwksURL string) (jwk.Set, error) {
+	c.mu.Lock()
+	cached, ok := c.sets[jwksURL]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetched) < c.ttl {
+		return cached.set, nil
+	}
+	set, err := jwk.Fetch(ctx, jwksURL, jwk.WithHTTPClient(c.client))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch JWKS from %s: %w", jwksURL, err)
+	}
+	c.mu.Lock()
+	c.sets[jwksURL] = cachedJWKS{set, time.Now()}
+	c.mu.Unlock()
+	return set, nil
+}
+
+// Verify verifies the signature and expiry of the auth's token against the
+// key set at jwksURL. Tokens without an expiry are invalid. Errors for an
+// invalid token wrap ErrInvalidToken.
+func (c *JWKSCache) Verify(ctx context.Context, a *RpkCloudAuth, jwksURL string) error {
+	if a.AuthToken == "" {
+		return fmt.Errorf("%w: cloud auth %q has no token", ErrInvalidToken, a.Name)
+	}
+	set, err := c.Fetch(ctx, jwksURL)
+	if err != nil {
+		return err
+	}
+	if _, err := jwt.Parse(
+		[]byte(a.AuthToken),
+		jwt.WithKeySet(set),
+		jwt.InferAlgorithmFromKey(true),
+		jwt.WithValidate(true),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+	); err != nil {
+		return fmt.Errorf("%w: cloud auth %q: %v", ErrInvalidToken, a.Name, err)
+	}
+	return nil
+}
+
+// Verify verifies the signature and expiry of the auth's token against the
+// key set at jwksURL, using a shared JWKSCache that refetches key sets hourly.
+// Errors for an invalid token wrap ErrInvalidToken.
+func (a *RpkCloudAuth) Verify(ctx context.Context, jwksURL string) error {
+	return defaultJWKSCache.Verify(ctx, a, jwksURL)
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkCloudAuthVerify(t *testing.T) {
+	newKey := func(kid string) (jwk.Key, jwk.Key) {
+		raw, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		priv, err := jwk.New(raw)
+		require.NoError(t, err)
+		pub, err := jwk.New(&raw.PublicKey)
+		require.NoError(t, err)
+		for _, k := range []jwk.Key{priv, pub} {
+			require.NoError(t, k.Set(jwk.KeyIDKey, kid))
+			require.NoError(t, k.Set(jwk.AlgorithmKey, jwa.RS256))
+		}
+		return priv, pub
+	}
+	priv, pub := newKey("trusted")
+	otherPriv, _ := newKey("trusted") // same key ID, different key
+
+	set := jwk.NewSet()
+	set.Add(pub)
+	var fetches atomic.Int32
+	slowEntered, slowRelease := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(slowEntered)
+			<-slowRelease
+			json.NewEncoder(w).Encode(set)
+			return
+		}
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(set)
+	}))
+	defer ts.Close()
+
+	sign := func(key jwk.Key, exp time.Time) string {
+		tok := jwt.New()
+		if !exp.IsZero() {
+			require.NoError(t, tok.Set(jwt.ExpirationKey, exp))
+		}
+		signed, err := jwt.Sign(tok, jwa.RS256, key)
+		require.NoError(t, err)
+		return string(signed)
+	}
+
+	cache := NewJWKSCache(ts.Client(), time.Hour)
+	ctx := context.Background()
+
+	valid := RpkCloudAuth{Name: "valid", AuthToken: sign(priv, time.Now().Add(time.Hour))}
+	require.NoError(t, cache.Verify(ctx, &valid, ts.URL+"/"))
+	require.NoError(t, cache.Verify(ctx, &valid, ts.URL+"/"))
+	require.Equal(t, int32(1), fetches.Load(), "JWKS was not cached")
+
+	for _, test := range []struct {
+		name  string
+		token string
+	}{
+		{"expired", sign(priv, time.Now().Add(-time.Hour))},
+		{"no expiry", sign(priv, time.Time{})},
+		{"untrusted key", sign(otherPriv, time.Now().Add(time.Hour))},
+		{"malformed", "not-a-jwt"},
+		{"missing", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := RpkCloudAuth{Name: test.name, AuthToken: test.token}
+			err := cache.Verify(ctx, &a, ts.URL+"/")
+			require.True(t, errors.Is(err, ErrInvalidToken), "got err %v", err)
+		})
+	}
+
+	// An expired cache entry is refetched.
+	uncached := NewJWKSCache(ts.Client(), 0)
+	require.NoError(t, uncached.Verify(ctx, &valid, ts.URL+"/"))
+	require.NoError(t, uncached.Verify(ctx, &valid, ts.URL+"/"))
+	require.Equal(t, int32(3), fetches.Load())
+
+	// Failing to fetch the JWKS is not an invalid token.
+	err := cache.Verify(ctx, &valid, ts.URL+"/missing")
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrInvalidToken))
+
+	// A slow fetch does not block verifying against a cached key set.
+	slowDone := make(chan error)
+	go func() {
+		_, err := cache.Fetch(ctx, ts.URL+"/slow")
+		slowDone <- err
+	}()
+	<-slowEntered
+	require.NoError(t, cache.Verify(ctx, &valid, ts.URL+"/"))
+	close(slowRelease)
+	require.NoError(t, <-slowDone)
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	rpkos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+)
+
+// WriteMinimal is like Write, but omits every field that is empty, such as
+// empty strings, zero durations, and empty sections, so that the file only
+// contains what was actually configured. Loading the minimal file results in
+// the same configuration as loading the full file. Unlike Write, the file is
+// always written.
+//
+// Fields that are set to the value rpk would default them to when loading,
+// such as brokers set to 127.0.0.1:9092, are kept: those defaults depend on
+// the redpanda.yaml, the environment, and globals such as
+// no_default_cluster, so omitting them could change the configuration that
+// a later load results in.
+func (y *RpkYaml) WriteMinimal(fs afero.Fs) error {
+	location, err := y.writeLocation()
+	if err != nil {
+		return err
+	}
+	unlock, err := rpkos.LockExclusive(fs, location+".lock")
+	if err != nil {
+		return fmt.Errorf("unable to lock %s for writing: %v", location, err)
+	}
+	defer unlock()
+	w, err := y.forWrite()
+	if err != nil {
+		return err
+	}
+	b, err := w.marshalMinimal()
+	if err != nil {
+		return fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	return y.replaceFile(fs, location, b)
+}
+
+func (y *RpkYaml) marshalMinimal() ([]byte, error) {
+	var n yaml.Node
+	if err := n.Encode(y); err != nil {
+		return nil, err
+	}
+	// When loading, the file is decoded over the default rpk.yaml, so
+	// top level fields that are non-zero by default must be kept even if
+	// they are empty: omitting an empty profile list would bring back
+	// the default profile.
+	def, err := defaultVirtualRpkYaml()
+	if err != nil {
+		return nil, err
+	}
+	defv := reflect.ValueOf(def)
+	pruneDefaults(&n, reflect.ValueOf(*y), func(field int) bool {
+		return !defv.Field(field).IsZero()
+	})
+	return yaml.Marshal(&n)
+}
+
+// pruneDefaults removes mapping entries from n, which is the encoding of v,
+// that decoding would default to anyway: zero values, empty lists and maps,
+// nil pointers, and structs whose fields were all pruned. Only the zero value
+// of a field is pruned, never its materialized default; see WriteMinimal.
+// Non-nil pointers are always kept, since an empty section can be
+// meaningful, e.g. "tls: {}" enables TLS. If keep is non-nil, it reports top
+// level fields that must be kept regardless of their value. Keys that do not
+// map to a struct field, such as unknown fields that are preserved in Extra,
+// are kept as is.
+func pruneDefaults(n *yaml.Node, v reflect.Value, keep func(field int) bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch {
+	case n.Kind == yaml.SequenceNode && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		for i, elem := range n.Content {
+			if i < v.Len() {
+				pruneDefaults(elem, v.Index(i), nil)
+			}
+		}
+		return
+	case n.Kind != yaml.MappingNode || v.Kind() != reflect.Struct:
+		return
+	}
+
+	fields := make(map[string]int) // yaml key => field index
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" || strings.Contains(opts, "inline") {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		fields[name] = i
+	}
+
+	kept := n.Content[:0]
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, val := n.Content[i], n.Content[i+1]
+		idx, ok := fields[k.Value]
+		if !ok {
+			kept = append(kept, k, val)
+			continue
+		}
+		fv := v.Field(idx)
+		pruneDefaults(val, fv, nil)
+		var drop bool
+		switch fv.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			drop = fv.IsNil()
+		case reflect.Slice, reflect.Map:
+			drop = fv.Len() == 0
+		default:
+			drop = fv.IsZero() || val.Kind == yaml.MappingNode && len(val.Content) == 0
+		}
+		if !drop || keep != nil && keep(idx) {
+			kept = append(kept, k, val)
+		}
+	}
+	n.Content = kept
+}
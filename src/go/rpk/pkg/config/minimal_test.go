@@ -0,0 +1,115 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRpkYamlWriteMinimal(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	full := fmt.Sprintf(`version: %d
+globals:
+    prompt: ""
+    no_default_cluster: false
+    command_timeout: 0s
+    dial_timeout: 5s
+    request_timeout_overhead: 0s
+    retry_timeout: 0s
+    fetch_max_wait: 0s
+    kafka_protocol_request_client_id: ""
+    journal: false
+current_profile: foo
+current_cloud_auth_org_id: ""
+current_cloud_auth_kind: ""
+profiles:
+    - name: foo
+      description: ""
+      prompt: ""
+      from_cloud: false
+      kafka_api:
+        brokers:
+            - 10.0.0.1:9092
+        tls: {}
+      admin_api: {}
+      schema_registry: {}
+      unknown_key: kept
+    - name: bar
+      description: second
+      prompt: ""
+      from_cloud: false
+      kafka_api:
+        brokers:
+            - 127.0.0.1:9092
+      admin_api:
+        addresses: []
+      schema_registry: {}
+cloud_auth: []
+`, currentRpkYAMLVersion)
+
+	load := func(t *testing.T, fs afero.Fs) string {
+		cfg, err := (&Params{ConfigFlag: path}).Load(fs)
+		require.NoError(t, err)
+		y := cfg.VirtualRpkYaml().Clone()
+		y.fileRaw = nil
+		b, err := yaml.Marshal(&y)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	fullFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fullFs, path, []byte(full), 0o644))
+	exp := load(t, fullFs)
+
+	y, _, err := LoadRpkYamlOrDefault(fullFs, path)
+	require.NoError(t, err)
+	minFs := afero.NewMemMapFs()
+	y.fileLocation = path
+	require.NoError(t, y.WriteMinimal(minFs))
+
+	raw, err := afero.ReadFile(minFs, path)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf(`version: %d
+globals:
+    dial_timeout: 5s
+current_profile: foo
+current_cloud_auth_org_id: ""
+profiles:
+    - name: foo
+      kafka_api:
+        brokers:
+            - 10.0.0.1:9092
+        tls: {}
+      unknown_key: kept
+    - name: bar
+      description: second
+      kafka_api:
+        brokers:
+            - 127.0.0.1:9092
+cloud_auth: []
+`, currentRpkYAMLVersion), string(raw), "brokers equal to their materialized default are kept")
+
+	require.Equal(t, exp, load(t, minFs))
+
+	// Minimal files load the same with LoadRpkYamlOrDefault as well;
+	// empty lists may come back as nil, which marshal the same.
+	again, _, err := LoadRpkYamlOrDefault(minFs, path)
+	require.NoError(t, err)
+	expB, err := yaml.Marshal(&y)
+	require.NoError(t, err)
+	gotB, err := yaml.Marshal(&again)
+	require.NoError(t, err)
+	require.Equal(t, string(expB), string(gotB))
+}
@@ -85,7 +85,11 @@ const (
 	xkindGlobal           // configuration for rpk.yaml globals
 )
 
-const currentRpkYAMLVersion = 5
+const currentRpkYAMLVersion = 29
+
+// EnvConfigDir is the environment variable that lists directories, separated
+// like PATH, that are searched for relative rpk.yaml includes.
+const EnvConfigDir = "RPK_CONFIG_DIR"
 
 type xflag struct {
 	path        string
@@ -107,6 +111,20 @@ func splitCommaIntoStrings(in string, dst *[]string) error {
 	return nil
 }
 
+// splitCommaIntoAddrs is splitCommaIntoStrings, additionally validating each
+// address.
+func splitCommaIntoAddrs(in string, dst *[]string, validate func(string) error) error {
+	if err := splitCommaIntoStrings(in, dst); err != nil {
+		return err
+	}
+	for _, addr := range *dst {
+		if err := validate(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func mkKafkaTLS(k *RpkKafkaAPI) *TLS {
 	if k.TLS == nil {
 		k.TLS = new(TLS)
@@ -142,7 +160,7 @@ var xflags = map[string]xflag{
 		xkindProfile,
 		func(v string, y *RpkYaml) error {
 			p := y.Profile(y.CurrentProfile)
-			return splitCommaIntoStrings(v, &p.KafkaAPI.Brokers)
+			return splitCommaIntoAddrs(v, &p.KafkaAPI.Brokers, validateKafkaBroker)
 		},
 	},
 	xKafkaTLSEnabled: {
@@ -237,7 +255,7 @@ var xflags = map[string]xflag{
 		xkindProfile,
 		func(v string, y *RpkYaml) error {
 			p := y.Profile(y.CurrentProfile)
-			return splitCommaIntoStrings(v, &p.AdminAPI.Addresses)
+			return splitCommaIntoAddrs(v, &p.AdminAPI.Addresses, func(a string) error { return validateHTTPAddr("admin API", a) })
 		},
 	},
 	xAdminTLSEnabled: {
@@ -300,7 +318,7 @@ var xflags = map[string]xflag{
 		xkindProfile,
 		func(v string, y *RpkYaml) error {
 			p := y.Profile(y.CurrentProfile)
-			return splitCommaIntoStrings(v, &p.SR.Addresses)
+			return splitCommaIntoAddrs(v, &p.SR.Addresses, func(a string) error { return validateHTTPAddr("schema registry", a) })
 		},
 	},
 	xSchemaRegistryTLSEnabled: {
@@ -465,6 +483,17 @@ var xflags = map[string]xflag{
 			return nil
 		},
 	},
+
+	"globals.journal": {
+		"globals.journal",
+		"false",
+		xkindGlobal,
+		func(v string, y *RpkYaml) error {
+			b, err := strconv.ParseBool(v)
+			y.Globals.Journal = b
+			return err
+		},
+	},
 }
 
 // XFlags returns the list of -X flags that are supported by rpk.
@@ -538,6 +567,15 @@ type Params struct {
 	// FlagOverrides are any flag-specified config overrides.
 	FlagOverrides []string
 
+	// ConfigKey is the passphrase used to decrypt secrets in an rpk.yaml
+	// written with WriteEncrypted. If empty, RPK_CONFIG_KEY is used.
+	ConfigKey []byte
+
+	// IncludeDirs are directories searched for relative rpk.yaml includes
+	// that do not exist relative to the including file. If empty, the
+	// directories in RPK_CONFIG_DIR are used.
+	IncludeDirs []string
+
 	loggerOnce sync.Once
 	logger     *zap.Logger
 
@@ -600,7 +638,8 @@ sasl.mechanism=SCRAM-SHA-256
   The SASL mechanism to use for authentication. This can be either SCRAM-SHA-256
   or SCRAM-SHA-512. Note that with Redpanda, the Admin API can be configured to
   require basic authentication with your Kafka API SASL credentials. This
-  defaults to SCRAM-SHA-256 if no mechanism is specified.
+  defaults to SCRAM-SHA-256 if no mechanism is specified. The mechanism is
+  case insensitive and surrounding whitespace is ignored.
 
 user=username
   The SASL username to use for authentication. This is also used for the admin
@@ -608,7 +647,10 @@ user=username
 
 pass=password
   The SASL password to use for authentication. This is also used for the admin
-  API if you have configured it to require basic authentication.
+  API if you have configured it to require basic authentication. The password
+  can be a reference: file:/path/to/secret reads the password from a file, and
+  env:VAR reads the password from an environment variable. Use literal:VALUE
+  for a password that itself starts with file:, env:, or literal:.
 
 admin.hosts=localhost:9644,rp.example.com:9644
   A comma separated list of host:ports that rpk talks to for the Admin API.
@@ -712,6 +754,11 @@ globals.kafka_protocol_request_client_id=rpk
   requests to Redpanda. This client ID shows up in Redpanda logs and metrics,
   changing it can be useful if you want to have your own rpk client stand out
   from others that may be hitting the cluster.
+
+globals.journal=false
+  A boolean that enables appending a timestamped summary of every rpk.yaml
+  write (profiles added or removed, the current profile switched) to a
+  "rpk.yaml.journal" file next to the rpk.yaml. Secrets are never journaled.
 `
 }
 
@@ -748,6 +795,7 @@ globals.request_timeout_overhead=duration(10s,1m,2h)
 globals.retry_timeout=duration(30s,1m,2h)
 globals.fetch_max_wait=duration(5s,1m,2h)
 globals.kafka_protocol_request_client_id=rpk
+globals.journal=boolean
 `
 }
 
@@ -944,6 +992,7 @@ func (p *Params) Load(fs afero.Fs) (*Config, error) {
 	}
 
 	c.migrateProfileNamespace()                          // migrate old cloud_cluster.namespace to cloud_cluster.resource_group.
+	c.rpkYaml.applyCloudDefaults()                       // default unset cloud_cluster fields in the virtual rpk.yaml from cloud_defaults
 	if err := c.promptDeleteOldRpkYaml(fs); err != nil { // delete auths with no org/orgID, and profiles with no auth
 		return nil, err
 	}
@@ -966,15 +1015,61 @@ func (p *Params) Load(fs afero.Fs) (*Config, error) {
 	c.addUnsetRedpandaDefaults(false) // merge from Virtual redpanda.yaml redpanda section to rpk section (picks up original redpanda.yaml defaults)
 	c.mergeRedpandaIntoRpk()          // merge from redpanda.yaml rpk section back to rpk.yaml, picks up final redpanda.yaml defaults
 	c.fixSchemePorts()                // strip any scheme, default any missing ports
+	c.applyInsecure()                 // disable TLS verification if the profile is insecure
+	if err := c.normalizeSASLMechanism(); err != nil {
+		return nil, err
+	}
+	if err := c.resolveSASLPassword(fs); err != nil {
+		return nil, err
+	}
 	c.parseDevOverrides()
 
 	if !c.rpkYaml.Globals.NoDefaultCluster {
 		c.ensureBrokerAddrs()
 	}
 
+	c.logLoaded()
 	return c, nil
 }
 
+// applyInsecure disables TLS verification for the Kafka and Admin APIs of the
+// virtual profile if the profile is marked insecure. An insecure profile is
+// easy to forget about, so we warn every time it is used. This does not
+// enable TLS: only APIs that already use TLS skip verification.
+func (c *Config) applyInsecure() {
+	p := c.VirtualProfile()
+	if p == nil || !p.Insecure {
+		return
+	}
+	for _, t := range []*TLS{p.KafkaAPI.TLS, p.AdminAPI.TLS} {
+		if t != nil {
+			t.InsecureSkipVerify = true
+		}
+	}
+	c.p.Logger().Warn("TLS verification is disabled for the Kafka and Admin APIs because the profile is marked insecure", zap.String("profile", p.Name))
+}
+
+// logLoaded logs the profile and cloud auth that Load resolved. Nothing
+// secret is logged: addresses and names are fine, credentials never are.
+func (c *Config) logLoaded() {
+	l := c.p.Logger()
+	if !l.Core().Enabled(zapcore.DebugLevel) {
+		return
+	}
+	if vp := c.VirtualProfile(); vp != nil {
+		l.Debug("using rpk profile",
+			zap.String("profile", vp.Name),
+			zap.Strings("brokers", vp.KafkaAPI.Brokers),
+			zap.Strings("admin_addresses", vp.AdminAPI.Addresses),
+			zap.Strings("schema_registry_addresses", vp.SR.Addresses),
+			zap.Bool("from_cloud", vp.FromCloud),
+		)
+	}
+	if a := c.rpkYaml.CurrentAuth(); a != nil {
+		l.Debug("using cloud auth", zap.String("auth", a.Name), zap.String("org_id", a.OrgID), zap.String("kind", a.Kind))
+	}
+}
+
 // SugarLogger returns Logger().Sugar().
 func (p *Params) SugarLogger() *zap.SugaredLogger {
 	return p.Logger().Sugar()
@@ -1085,15 +1180,21 @@ func (p *Params) readRpkConfig(fs afero.Fs, c *Config) error {
 		// whereas there as only three redpanda.yaml creation commands.
 		// Since they do not overlap, it is ok to save this config flag
 		// as the file location for both of these.
+		p.Logger().Debug("rpk.yaml does not exist", zap.String("path", abs))
 		c.rpkYaml.fileLocation = abs
 		c.rpkYamlActual.fileLocation = abs
 		return nil
 	}
+	p.Logger().Debug("loading rpk.yaml", zap.String("path", abs))
 	before := c.rpkYaml
 	if err := yaml.Unmarshal(file, &c.rpkYaml); err != nil {
 		return fmt.Errorf("unable to yaml decode %s: %v", path, err)
 	}
-	if c.rpkYaml.Version < 1 {
+	unversioned := isUnversionedRpkYaml(file)
+	if unversioned {
+		c.rpkYaml.Version = 0 // the decode above kept our default version
+	}
+	if c.rpkYaml.Version < 1 && !unversioned {
 		if p.ConfigFlag == "" {
 			return fmt.Errorf("%s is not in the expected rpk.yaml format", def)
 		}
@@ -1102,26 +1203,201 @@ func (p *Params) readRpkConfig(fs afero.Fs, c *Config) error {
 	} else if c.rpkYaml.Version < currentRpkYAMLVersion {
 		c.rpkYaml.Version = currentRpkYAMLVersion
 	} else if c.rpkYaml.Version > currentRpkYAMLVersion {
-		return fmt.Errorf("%s is using a newer rpk.yaml format than we understand, please upgrade rpk", def)
+		return fmt.Errorf("%s is using a newer rpk.yaml format (version %d) than we understand (up to version %d), please upgrade rpk", abs, c.rpkYaml.Version, currentRpkYAMLVersion)
 	}
 	yaml.Unmarshal(file, &c.rpkYamlActual)
 	c.rpkYamlActual.Version = c.rpkYaml.Version
+	if err := c.rpkYaml.trimNames(); err != nil {
+		return fmt.Errorf("unable to load %s: %w", abs, err)
+	}
+	c.rpkYamlActual.trimNames()
+	if unversioned {
+		c.rpkYaml.migrateUnversioned()
+		c.rpkYamlActual.migrateUnversioned()
+	}
+	c.rpkYaml.resolvePaths(filepath.Dir(abs))
+	if err := loadRpkIncludes(fs, &c.rpkYaml, abs, p.includeDirs(), nil); err != nil {
+		return err
+	}
+	if err := p.decryptRpkSecrets(&c.rpkYaml, &c.rpkYamlActual); err != nil {
+		return fmt.Errorf("unable to load %s: %w", abs, err)
+	}
+	if err := c.rpkYaml.resolveParents(); err != nil {
+		return fmt.Errorf("unable to resolve profile parents in %s: %v", abs, err)
+	}
 
 	if p.Profile != "" {
-		if c.rpkYaml.Profile(p.Profile) == nil {
-			return fmt.Errorf("selected profile %q does not exist", p.Profile)
+		if !c.rpkYaml.HasProfile(p.Profile) {
+			return fmt.Errorf("%w: %q, selected with --profile", ErrProfileNotFound, p.Profile)
 		}
+		p.Logger().Debug("using --profile as the current profile", zap.String("profile", p.Profile))
 		c.rpkYaml.CurrentProfile = p.Profile
 		c.rpkYamlActual.CurrentProfile = p.Profile
+	} else {
+		c.rpkYaml.useEnvironmentProfile()
 	}
+	if c.rpkYamlActual.CurrentProfile == "" {
+		c.rpkYaml.useImplicitProfile()
+	}
+	if c.rpkYamlActual.CurrentProfile == "" && !c.rpkYaml.HasProfile(c.rpkYaml.CurrentProfile) && len(c.rpkYaml.Profiles) > 1 {
+		c.noCurrentProfile = fmt.Errorf("unable to load %s: %w: select one of %s with 'rpk profile use' or --profile", abs, ErrNoCurrentProfile, strings.Join(c.rpkYaml.ProfileNames(), ", "))
+	}
+	if err := c.rpkYaml.Profile(c.rpkYaml.CurrentProfile).validateAddrs(); err != nil {
+		return fmt.Errorf("invalid profile %q in %s: %w", c.rpkYaml.CurrentProfile, abs, err)
+	}
+	// Loading a profile uses it. The stamp is only persisted if
+	// something else is written; see RpkProfile.Touch.
+	c.rpkYaml.Profile(c.rpkYaml.CurrentProfile).Touch()
+	c.rpkYamlActual.Profile(c.rpkYaml.CurrentProfile).Touch()
 	c.rpkYamlExists = true
 	c.rpkYaml.fileLocation = abs
 	c.rpkYamlActual.fileLocation = abs
 	c.rpkYaml.fileRaw = file
 	c.rpkYamlActual.fileRaw = file
+	if unversioned {
+		if err := c.rpkYamlActual.Write(fs); err != nil {
+			return fmt.Errorf("unable to write migrated %s: %v", abs, err)
+		}
+	}
+	return nil
+}
+
+// decryptRpkSecrets decrypts any encrypted secrets in the virtual rpk.yaml.
+// Like secret references, only the virtual rpk.yaml is decrypted so that
+// writing the actual rpk.yaml keeps the secrets encrypted. Both remember the
+// key, so that any new secrets are encrypted when written.
+func (p *Params) decryptRpkSecrets(vir, act *RpkYaml) error {
+	if !vir.HasEncryptedSecrets() {
+		return nil
+	}
+	key := p.EffectiveConfigKey()
+	if len(key) == 0 {
+		return fmt.Errorf("secrets are encrypted, set %s to the passphrase used to encrypt them", EnvConfigKey)
+	}
+	if err := vir.DecryptSecrets(key); err != nil {
+		return err
+	}
+	vir.encryptKey = key
+	act.encryptKey = key
 	return nil
 }
 
+// EffectiveConfigKey returns the passphrase used to encrypt and decrypt
+// secrets: ConfigKey, or RPK_CONFIG_KEY if ConfigKey is empty.
+func (p *Params) EffectiveConfigKey() []byte {
+	if len(p.ConfigKey) > 0 {
+		return p.ConfigKey
+	}
+	return []byte(os.Getenv(EnvConfigKey))
+}
+
+// includeDirs returns the directories to search for relative includes.
+func (p *Params) includeDirs() []string {
+	if len(p.IncludeDirs) > 0 {
+		return p.IncludeDirs
+	}
+	return filepath.SplitList(os.Getenv(EnvConfigDir))
+}
+
+// resolveInclude returns the absolute path of include, which is listed in
+// the file at path. Absolute includes are used as is. Relative includes are
+// resolved against the directory of the including file first and, if no
+// file exists there, against each of dirs in order.
+func resolveInclude(fs afero.Fs, include, path string, dirs []string) (string, error) {
+	local, err := filepath.Abs(resolvePath(include, filepath.Dir(path)))
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve include %q in %s: %v", include, path, err)
+	}
+	if filepath.IsAbs(resolvePath(include, "")) {
+		return local, nil
+	}
+	if exists, _ := afero.Exists(fs, local); exists {
+		return local, nil
+	}
+	searched := []string{local}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(resolvePath(include, resolvePath(dir, "")))
+		if err != nil {
+			continue
+		}
+		if exists, _ := afero.Exists(fs, abs); exists {
+			return abs, nil
+		}
+		searched = append(searched, abs)
+	}
+	return "", fmt.Errorf("unable to find %q included from %s, searched: %s", include, path, strings.Join(searched, ", "))
+}
+
+// loadRpkIncludes merges the profiles and cloud auths of every file included
+// by y, which was loaded from path, into y. Relative includes are searched
+// for in dirs if they do not exist next to the including file; see
+// resolveInclude. Included files can include other files; chain contains the
+// files that are currently being included so that we can detect cycles.
+// Included profiles and auths only exist in the virtual rpk.yaml and are
+// never written back to the including file.
+func loadRpkIncludes(fs afero.Fs, y *RpkYaml, path string, dirs, chain []string) error {
+	chain = append(chain, path)
+	for _, include := range y.Includes {
+		abs, err := resolveInclude(fs, include, path, dirs)
+		if err != nil {
+			return err
+		}
+		for _, seen := range chain {
+			if seen == abs {
+				return fmt.Errorf("rpk.yaml include cycle detected: %s -> %s", strings.Join(chain, " -> "), abs)
+			}
+		}
+		_, file, err := readFile(fs, abs)
+		if err != nil {
+			return fmt.Errorf("unable to read %s included from %s: %v", abs, path, err)
+		}
+		var inc RpkYaml
+		if err := yaml.Unmarshal(file, &inc); err != nil {
+			return fmt.Errorf("unable to yaml decode %s included from %s: %v", abs, path, err)
+		}
+		if inc.Version > currentRpkYAMLVersion {
+			return fmt.Errorf("%s included from %s is using a newer rpk.yaml format (version %d) than we understand (up to version %d), please upgrade rpk", abs, path, inc.Version, currentRpkYAMLVersion)
+		}
+		if err := inc.trimNames(); err != nil {
+			return fmt.Errorf("unable to load %s included from %s: %w", abs, path, err)
+		}
+		inc.resolvePaths(filepath.Dir(abs))
+		if err := loadRpkIncludes(fs, &inc, abs, dirs, chain); err != nil {
+			return err
+		}
+		y.Merge(inc, false)
+	}
+	return nil
+}
+
+// isUnversionedRpkYaml returns whether a file is an old rpk.yaml that
+// predates the version field, rather than a redpanda.yaml or some other file:
+// the file must have no version, must contain rpk.yaml top level keys, and
+// must not contain redpanda.yaml top level keys.
+func isUnversionedRpkYaml(file []byte) bool {
+	var m map[string]any
+	if err := yaml.Unmarshal(file, &m); err != nil {
+		return false
+	}
+	if _, ok := m["version"]; ok {
+		return false
+	}
+	for _, k := range []string{"redpanda", "rpk", "pandaproxy", "pandaproxy_client", "schema_registry", "schema_registry_client"} {
+		if _, ok := m[k]; ok {
+			return false
+		}
+	}
+	for _, k := range []string{"globals", "current_profile", "profiles", "cloud_auth", "includes"} {
+		if _, ok := m[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Params) readRedpandaConfig(fs afero.Fs, c *Config) error {
 	paths := []string{p.ConfigFlag}
 	if p.ConfigFlag == "" {
@@ -1144,6 +1420,7 @@ func (p *Params) readRedpandaConfig(fs afero.Fs, c *Config) error {
 			return fmt.Errorf("unable to yaml decode %s: %v", path, err)
 		}
 		yaml.Unmarshal(file, &c.redpandaYamlActual)
+		p.Logger().Debug("loaded redpanda.yaml", zap.String("path", abs))
 
 		c.redpandaYamlExists = true
 		c.redpandaYaml.fileLocation = abs
@@ -1477,7 +1754,16 @@ func (c *Config) mergeRedpandaIntoRpk() {
 
 // Similar to backcompat flags, we first capture old env vars and then new
 // ones. New env vars are the same as -X, uppercased, s/./_/.
+//
+// Env vars for address lists (brokers, admin hosts, schema registry hosts)
+// that are set but empty are treated as unset: we keep the addresses from
+// the file rather than overriding to zero addresses.
 func envOverrides() []string {
+	addrLists := map[string]bool{
+		xKafkaBrokers:        true,
+		xAdminHosts:          true,
+		xSchemaRegistryHosts: true,
+	}
 	var envOverrides []string
 	for _, envMapping := range []struct {
 		old       string
@@ -1500,20 +1786,68 @@ func envOverrides() []string {
 		{"RPK_CLOUD_CLIENT_SECRET", xCloudClientSecret},
 	} {
 		if v, exists := os.LookupEnv(envMapping.old); exists {
+			if v == "" && addrLists[envMapping.targetKey] {
+				continue
+			}
 			envOverrides = append(envOverrides, envMapping.targetKey+"="+v)
 		}
 	}
 	for _, k := range XFlags() {
 		targetKey := k
-		k = strings.ReplaceAll(k, ".", "_")
-		k = strings.ToUpper(k)
-		if v, exists := os.LookupEnv("RPK_" + k); exists {
+		if v, exists := os.LookupEnv(xflagEnv(k)); exists {
+			if v == "" && addrLists[targetKey] {
+				continue
+			}
 			envOverrides = append(envOverrides, targetKey+"="+v)
 		}
 	}
 	return envOverrides
 }
 
+// xflagEnv returns the environment variable that overrides the given -X flag,
+// e.g. RPK_TLS_CA for tls.ca.
+func xflagEnv(x string) string {
+	return "RPK_" + strings.ToUpper(strings.ReplaceAll(x, ".", "_"))
+}
+
+// Environ returns the profile's connection settings as KEY=VALUE environment
+// variables that rpk reads as overrides (see 'rpk -X help'). Empty settings
+// are skipped. This includes the unredacted SASL password and is meant for
+// use in a local shell.
+func (p *RpkProfile) Environ() []string {
+	var env []string
+	add := func(x, v string) {
+		if v != "" {
+			env = append(env, xflagEnv(x)+"="+v)
+		}
+	}
+	addTLS := func(t *TLS, enabled, insecure, ca, cert, key string) {
+		if t == nil {
+			return
+		}
+		add(enabled, "true")
+		if t.InsecureSkipVerify {
+			add(insecure, "true")
+		}
+		add(ca, t.TruststoreFile)
+		add(cert, t.CertFile)
+		add(key, t.KeyFile)
+	}
+
+	add(xKafkaBrokers, strings.Join(p.KafkaAPI.Brokers, ","))
+	addTLS(p.KafkaAPI.TLS, xKafkaTLSEnabled, xKafkaTLSInsecure, xKafkaCACert, xKafkaClientCert, xKafkaClientKey)
+	if s := p.KafkaAPI.SASL; s != nil {
+		add(xKafkaSASLMechanism, s.Mechanism)
+		add(xKafkaSASLUser, s.User)
+		add(xKafkaSASLPass, s.Password)
+	}
+	add(xAdminHosts, strings.Join(p.AdminAPI.Addresses, ","))
+	addTLS(p.AdminAPI.TLS, xAdminTLSEnabled, xAdminTLSInsecure, xAdminCACert, xAdminClientCert, xAdminClientKey)
+	add(xSchemaRegistryHosts, strings.Join(p.SR.Addresses, ","))
+	addTLS(p.SR.TLS, xSchemaRegistryTLSEnabled, xSchemaRegistryTLSInsecure, xSchemaRegistryCACert, xSchemaRegistryClientCert, xSchemaRegistryClientKey)
+	return env
+}
+
 // processes first env and then flag overrides into our virtual rpk yaml.
 func (p *Params) processOverrides(c *Config) error {
 	parse := func(isEnv bool, kvs []string) error {
@@ -1535,6 +1869,8 @@ func (p *Params) processOverrides(c *Config) error {
 			if err := xf.parse(v, &c.rpkYaml); err != nil {
 				return fmt.Errorf("%s config key %q: %s", from, k, err)
 			}
+			// Values can be secrets, so we only log the key.
+			p.Logger().Debug("applied config override", zap.String("from", from), zap.String("key", k))
 		}
 		return nil
 	}
@@ -1582,6 +1918,63 @@ func (c *Config) addUnsetRedpandaDefaults(actual bool) {
 	}
 }
 
+// validateAddrs returns an error describing every invalid address in the
+// profile; see validateKafkaBroker and validateHTTPAddr.
+func (p *RpkProfile) validateAddrs() error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	for _, b := range p.KafkaAPI.Brokers {
+		errs = append(errs, validateKafkaBroker(b))
+	}
+	for _, a := range p.AdminAPI.Addresses {
+		errs = append(errs, validateHTTPAddr("admin API", a))
+	}
+	for _, a := range p.SR.Addresses {
+		errs = append(errs, validateHTTPAddr("schema registry", a))
+	}
+	return errors.Join(errs...)
+}
+
+// validateKafkaBroker returns an error if b is not "host" or "host:port".
+func validateKafkaBroker(b string) error {
+	scheme, _, port, err := rpknet.SplitSchemeHostPort(b)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid kafka broker address %q: must be \"host\" or \"host:port\"", b)
+	case scheme != "":
+		return fmt.Errorf("invalid kafka broker address %q: brokers must not have a scheme, use \"host\" or \"host:port\"", b)
+	case !isValidPort(port):
+		return fmt.Errorf("invalid kafka broker address %q: port must be between 1 and 65535", b)
+	}
+	return nil
+}
+
+// validateHTTPAddr returns an error if a is not "host", "host:port", or an
+// http or https URL.
+func validateHTTPAddr(api, a string) error {
+	scheme, _, port, err := rpknet.SplitSchemeHostPort(a)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid %s address %q: must be \"host\", \"host:port\", or an http or https URL", api, a)
+	case scheme != "" && scheme != "http" && scheme != "https":
+		return fmt.Errorf("invalid %s address %q: unsupported scheme %q, only http and https are supported", api, a, scheme)
+	case !isValidPort(port):
+		return fmt.Errorf("invalid %s address %q: port must be between 1 and 65535", api, a)
+	}
+	return nil
+}
+
+// isValidPort returns whether port is empty or within 1 to 65535.
+func isValidPort(port string) bool {
+	if port == "" {
+		return true
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
 func (c *Config) fixSchemePorts() error {
 	for i, k := range c.redpandaYaml.Rpk.KafkaAPI.Brokers {
 		_, host, port, err := rpknet.SplitSchemeHostPort(k)
@@ -1658,6 +2051,63 @@ func (c *Config) fixSchemePorts() error {
 	return nil
 }
 
+// We normalize the SASL mechanism of the current profile so that mechanisms
+// with odd casing or stray spaces work, and so that typos fail early rather
+// than once we try to connect.
+func (c *Config) normalizeSASLMechanism() error {
+	if err := c.redpandaYaml.Rpk.KafkaAPI.SASL.normalizeMechanism(); err != nil {
+		return fmt.Errorf("redpanda.yaml rpk.kafka_api.sasl: %w", err)
+	}
+	if err := c.redpandaYaml.Rpk.KafkaAPI.normalizeBrokerSASL(); err != nil {
+		return fmt.Errorf("redpanda.yaml rpk.kafka_api.%w", err)
+	}
+	p := c.rpkYaml.Profile(c.rpkYaml.CurrentProfile)
+	if err := p.KafkaAPI.SASL.normalizeMechanism(); err != nil {
+		return fmt.Errorf("profile %q kafka_api.sasl: %w", p.Name, err)
+	}
+	if err := p.KafkaAPI.normalizeBrokerSASL(); err != nil {
+		return fmt.Errorf("profile %q kafka_api.%w", p.Name, err)
+	}
+	return nil
+}
+
+// We resolve secret references (see ResolveSecret) in the SASL password of
+// the current profile, including per-broker overrides. Only the virtual
+// configuration is resolved, meaning the reference is what is kept if the
+// actual rpk.yaml is written. The redpanda.yaml and rpk.yaml can share the
+// same SASL struct after merging, and we must only resolve a password once:
+// "literal:file:x" must not become the contents of x.
+func (c *Config) resolveSASLPassword(fs afero.Fs) error {
+	type toResolve struct {
+		sasl *SASL
+		name string
+	}
+	p := c.rpkYaml.Profile(c.rpkYaml.CurrentProfile)
+	rs := []toResolve{
+		{c.redpandaYaml.Rpk.KafkaAPI.SASL, "redpanda.yaml rpk.kafka_api.sasl.password"},
+		{p.KafkaAPI.SASL, fmt.Sprintf("profile %q kafka_api.sasl.password", p.Name)},
+	}
+	for addr, s := range c.redpandaYaml.Rpk.KafkaAPI.BrokerSASL {
+		rs = append(rs, toResolve{s, fmt.Sprintf("redpanda.yaml rpk.kafka_api.broker_sasl %q password", addr)})
+	}
+	for addr, s := range p.KafkaAPI.BrokerSASL {
+		rs = append(rs, toResolve{s, fmt.Sprintf("profile %q kafka_api.broker_sasl %q password", p.Name, addr)})
+	}
+	resolved := make(map[*SASL]bool)
+	for _, r := range rs {
+		if r.sasl == nil || resolved[r.sasl] {
+			continue
+		}
+		resolved[r.sasl] = true
+		pass, err := ResolveSecret(fs, r.sasl.Password)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+		r.sasl.Password = pass
+	}
+	return nil
+}
+
 func (c *Config) addConfigToProfiles() {
 	for i := range c.rpkYaml.Profiles {
 		c.rpkYaml.Profiles[i].c = c
@@ -1994,6 +2444,10 @@ func getFieldByTag(tag string, v reflect.Value) (reflect.Value, reflect.Value, e
 	}
 
 	for _, i := range inlines {
+		// Inline maps capture unknown fields and have no tags.
+		if v.Field(i).Kind() != reflect.Struct {
+			continue
+		}
 		if v, _, err := getFieldByTag(tag, v.Field(i)); err == nil {
 			return v, reflect.Value{}, nil
 		}
@@ -1,14 +1,23 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
 )
 
 func TestParams_RedpandaYamlWrite(t *testing.T) {
@@ -723,7 +732,7 @@ rpk:
 pandaproxy: {}
 schema_registry: {}
 `,
-			expVirtualRpk: `version: 5
+			expVirtualRpk: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -733,6 +742,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: default
 current_cloud_auth_org_id: default-org-no-id
 current_cloud_auth_kind: ""
@@ -815,7 +825,7 @@ rpk:
     tune_disk_write_cache: true
     tune_disk_irq: true
 `,
-			expVirtualRpk: `version: 5
+			expVirtualRpk: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -825,6 +835,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: default
 current_cloud_auth_org_id: default-org-no-id
 current_cloud_auth_kind: ""
@@ -857,7 +868,7 @@ cloud_auth:
 		// * admin api is defaulted, using kafka broker ip
 		{
 			name: "rpk.yaml exists",
-			rpkYaml: `version: 5
+			rpkYaml: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -867,6 +878,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: foo
 current_cloud_auth_org_id: fizz-org-id
 current_cloud_auth_kind: sso
@@ -914,7 +926,7 @@ rpk:
 pandaproxy: {}
 schema_registry: {}
 `,
-			expVirtualRpk: `version: 5
+			expVirtualRpk: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -924,6 +936,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: foo
 current_cloud_auth_org_id: fizz-org-id
 current_cloud_auth_kind: sso
@@ -975,7 +988,7 @@ rpk:
     tune_disk_write_cache: true
     tune_disk_irq: true
 `,
-			rpkYaml: `version: 5
+			rpkYaml: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -985,6 +998,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: foo
 current_cloud_auth_org_id: ""
 current_cloud_auth_kind: ""
@@ -1025,7 +1039,7 @@ rpk:
     tune_disk_irq: true
 `,
 
-			expVirtualRpk: `version: 5
+			expVirtualRpk: `version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -1035,6 +1049,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: foo
 current_cloud_auth_org_id: default-org-no-id
 current_cloud_auth_kind: ""
@@ -1094,6 +1109,14 @@ cloud_auth:
 					m[actPath] = testfs.RFile(test.redpandaYaml)
 				}
 			}
+			// Loading touches the current profile, which is not
+			// what this test is about.
+			if p := cfg.VirtualProfile(); p != nil {
+				p.LastUsedAt = Timestamp{}
+			}
+			if p := cfg.ActualProfile(); p != nil {
+				p.LastUsedAt = Timestamp{}
+			}
 			{
 				mat := cfg.VirtualRpkYaml()
 				mat.Write(fs)
@@ -1117,6 +1140,819 @@ cloud_auth:
 	}
 }
 
+func TestMaterialize(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		path: {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [file:9092]
+        sasl:
+            user: file-user
+            mechanism: SCRAM-SHA-256
+      admin_api:
+        addresses: [file:9644]
+    - name: bar
+      kafka_api:
+        brokers: [bar:9092]
+`},
+	})
+
+	for _, test := range []struct {
+		name  string
+		env   map[string]string
+		flags Overrides
+
+		expProfile string
+		expBrokers []string
+		expUser    string
+		expAdmin   []string
+	}{
+		{
+			name:       "file",
+			expProfile: "foo",
+			expBrokers: []string{"file:9092"},
+			expUser:    "file-user",
+			expAdmin:   []string{"file:9644"},
+		},
+		{
+			name:       "env over file",
+			env:        map[string]string{"RPK_BROKERS": "env:9092", "RPK_USER": "env-user"},
+			expProfile: "foo",
+			expBrokers: []string{"env:9092"},
+			expUser:    "env-user",
+			expAdmin:   []string{"file:9644"},
+		},
+		{
+			name:       "flags over env",
+			env:        map[string]string{"RPK_BROKERS": "env:9092", "RPK_USER": "env-user"},
+			flags:      Overrides{X: []string{"brokers=flag:9092", "admin.hosts=flag:9644"}},
+			expProfile: "foo",
+			expBrokers: []string{"flag:9092"},
+			expUser:    "env-user",
+			expAdmin:   []string{"flag:9644"},
+		},
+		{
+			name:       "later flags win",
+			flags:      Overrides{X: []string{"brokers=first:9092", "brokers=second:9092"}},
+			expProfile: "foo",
+			expBrokers: []string{"second:9092"},
+			expUser:    "file-user",
+			expAdmin:   []string{"file:9644"},
+		},
+		{
+			name:       "selected profile",
+			env:        map[string]string{"RPK_USER": "env-user"},
+			flags:      Overrides{Profile: "bar"},
+			expProfile: "bar",
+			expBrokers: []string{"bar:9092"},
+			expUser:    "env-user",
+			expAdmin:   []string{"bar:9644"}, // defaulted from the broker host
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+			p, err := Materialize(fs, path, test.flags)
+			require.NoError(t, err)
+			require.Equal(t, test.expProfile, p.Name)
+			require.Equal(t, test.expBrokers, p.KafkaAPI.Brokers)
+			require.Equal(t, test.expAdmin, p.AdminAPI.Addresses)
+			var user string
+			if p.KafkaAPI.SASL != nil {
+				user = p.KafkaAPI.SASL.User
+			}
+			require.Equal(t, test.expUser, user)
+		})
+	}
+
+	_, err := Materialize(fs, path, Overrides{X: []string{"brokers"}})
+	require.ErrorContains(t, err, "is not a key=value")
+	_, err = Materialize(fs, path, Overrides{Profile: "missing"})
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+}
+
+func TestEnvAddressOverrides(t *testing.T) {
+	defaultRpkPath, err := DefaultRpkYamlPath()
+	if err != nil {
+		t.Fatalf("unable to load default rpk yaml path: %v", err)
+	}
+	const rpkYaml = `version: 5
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers:
+            - 10.0.0.1:9092
+      admin_api:
+        addresses:
+            - 10.0.0.1:9644
+`
+	envs := []string{"RPK_BROKERS", "REDPANDA_BROKERS", "RPK_ADMIN_HOSTS", "REDPANDA_API_ADMIN_ADDRS"}
+	for _, test := range []struct {
+		name      string
+		env       map[string]string
+		expKafkaa []string
+		expAdmin  []string
+	}{
+		{
+			name:      "unset",
+			expKafkaa: []string{"10.0.0.1:9092"},
+			expAdmin:  []string{"10.0.0.1:9644"},
+		},
+		{
+			name:      "empty",
+			env:       map[string]string{"RPK_BROKERS": "", "RPK_ADMIN_HOSTS": ""},
+			expKafkaa: []string{"10.0.0.1:9092"},
+			expAdmin:  []string{"10.0.0.1:9644"},
+		},
+		{
+			name:      "empty old",
+			env:       map[string]string{"REDPANDA_BROKERS": "", "REDPANDA_API_ADMIN_ADDRS": ""},
+			expKafkaa: []string{"10.0.0.1:9092"},
+			expAdmin:  []string{"10.0.0.1:9644"},
+		},
+		{
+			name:      "override",
+			env:       map[string]string{"RPK_BROKERS": "10.0.0.2:9092,10.0.0.3", "RPK_ADMIN_HOSTS": "10.0.0.2"},
+			expKafkaa: []string{"10.0.0.2:9092", "10.0.0.3:9092"},
+			expAdmin:  []string{"10.0.0.2:9644"},
+		},
+		{
+			name:      "new overrides old",
+			env:       map[string]string{"REDPANDA_BROKERS": "10.0.0.4:9092", "RPK_BROKERS": "10.0.0.5:9092"},
+			expKafkaa: []string{"10.0.0.5:9092"},
+			expAdmin:  []string{"10.0.0.1:9644"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, k := range envs {
+				t.Setenv(k, "")
+				os.Unsetenv(k)
+			}
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				defaultRpkPath: testfs.RFile(rpkYaml),
+			})
+			cfg, err := new(Params).Load(fs)
+			require.NoError(t, err)
+			p := cfg.VirtualProfile()
+			require.Equal(t, test.expKafkaa, p.KafkaAPI.Brokers)
+			require.Equal(t, test.expAdmin, p.AdminAPI.Addresses)
+		})
+	}
+}
+
+func TestLoadResolvesTLSPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	t.Setenv("RPK_TEST_CERT_DIR", "/ci/certs")
+	t.Setenv("RPK_TEST_CERT_NAME", "sr")
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": testfs.RFile(`version: 6
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        tls:
+            ca_file: ca.pem
+            cert_file: ~/client.pem
+            key_file: /abs/client.key
+      admin_api:
+        tls:
+            ca_file: certs/admin-ca.pem
+      schema_registry:
+        tls:
+            ca_file: $RPK_TEST_CERT_DIR/ca.pem
+            cert_file: ${RPK_TEST_CERT_DIR}/${RPK_TEST_CERT_NAME}.pem
+            key_file: $RPK_TEST_CERT_NAME.key
+`),
+	})
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+
+	p := cfg.VirtualProfile()
+	require.Equal(t, &TLS{
+		TruststoreFile: "/etc/rpk/ca.pem",
+		CertFile:       filepath.Join(home, "client.pem"),
+		KeyFile:        "/abs/client.key",
+	}, p.KafkaAPI.TLS)
+	require.Equal(t, &TLS{TruststoreFile: "/etc/rpk/certs/admin-ca.pem"}, p.AdminAPI.TLS)
+	require.Equal(t, &TLS{
+		TruststoreFile: "/ci/certs/ca.pem",
+		CertFile:       "/ci/certs/sr.pem",
+		KeyFile:        "/etc/rpk/sr.key",
+	}, p.SR.TLS)
+
+	// The actual file is left as written.
+	act := cfg.ActualProfile()
+	require.Equal(t, "ca.pem", act.KafkaAPI.TLS.TruststoreFile)
+	require.Equal(t, "~/client.pem", act.KafkaAPI.TLS.CertFile)
+}
+
+func TestLoadNormalizesSASLMechanism(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		mechanism string
+		flags     []string
+		exp       string
+		expErr    bool
+	}{
+		{name: "empty", mechanism: "", exp: ""},
+		{name: "already normalized", mechanism: "SCRAM-SHA-512", exp: "SCRAM-SHA-512"},
+		{name: "lowercase padded", mechanism: " scram-sha-256 ", exp: "SCRAM-SHA-256"},
+		{name: "mixed case tab", mechanism: "Plain\t", exp: "PLAIN"},
+		{name: "oauthbearer", mechanism: "oauthbearer", exp: "OAUTHBEARER"},
+		{name: "gssapi", mechanism: "GssApi", exp: "GSSAPI"},
+		{name: "from flag", mechanism: "PLAIN", flags: []string{"sasl.mechanism=scram-sha-512 "}, exp: "SCRAM-SHA-512"},
+		{name: "unknown", mechanism: "SCRAM-SHA-1", expErr: true},
+		{name: "unknown from flag", mechanism: "PLAIN", flags: []string{"sasl.mechanism=nope"}, expErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/rpk.yaml": testfs.RFile(fmt.Sprintf(`version: 29
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        sasl:
+            user: user
+            password: pass
+            mechanism: %q
+            gssapi:
+                keytab: /rpk.keytab
+`, test.mechanism)),
+			})
+			cfg, err := (&Params{ConfigFlag: "/rpk.yaml", FlagOverrides: test.flags}).Load(fs)
+			if test.expErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "valid mechanisms are: SCRAM-SHA-256, SCRAM-SHA-512, PLAIN, OAUTHBEARER, GSSAPI")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, cfg.VirtualProfile().KafkaAPI.SASL.Mechanism)
+		})
+	}
+}
+
+func TestLoadMigratesUnversionedRpkYaml(t *testing.T) {
+	defaultRpkPath, err := DefaultRpkYamlPath()
+	if err != nil {
+		t.Fatalf("unable to load default rpk yaml path: %v", err)
+	}
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		defaultRpkPath: testfs.RFile(`profiles:
+    - description: unnamed
+      kafka_api:
+        brokers:
+            - 10.0.0.1:9092
+    - name: bar
+      kafka_api:
+        brokers:
+            - 10.0.0.2:9092
+`),
+	})
+
+	cfg, err := new(Params).Load(fs)
+	require.NoError(t, err)
+	act, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	require.Equal(t, currentRpkYAMLVersion, act.Version)
+	require.Equal(t, DefaultProfileName, act.CurrentProfile)
+	require.Equal(t, "unnamed", act.Profile(DefaultProfileName).Description)
+	require.Equal(t, []string{"10.0.0.2:9092"}, act.Profile("bar").KafkaAPI.Brokers)
+	require.Equal(t, []string{"10.0.0.1:9092"}, cfg.VirtualProfile().KafkaAPI.Brokers)
+
+	migrated, err := afero.ReadFile(fs, defaultRpkPath)
+	require.NoError(t, err)
+	var y RpkYaml
+	require.NoError(t, yaml.Unmarshal(migrated, &y))
+	require.Equal(t, currentRpkYAMLVersion, y.Version)
+	require.Equal(t, DefaultProfileName, y.CurrentProfile)
+
+	// Loading again is a no-op.
+	_, err = new(Params).Load(fs)
+	require.NoError(t, err)
+	reloaded, err := afero.ReadFile(fs, defaultRpkPath)
+	require.NoError(t, err)
+	require.Equal(t, string(migrated), string(reloaded))
+}
+
+func TestLoadUnversionedNonRpkYaml(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/redpanda.yaml": testfs.RFile(`redpanda:
+    developer_mode: true
+`),
+	})
+	_, err := (&Params{ConfigFlag: "/redpanda.yaml"}).Load(fs)
+	require.NoError(t, err)
+	raw, err := afero.ReadFile(fs, "/redpanda.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "redpanda:\n    developer_mode: true\n", string(raw))
+}
+
+func TestLoadRpkIncludes(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		expErr  bool
+		expKafN map[string][]string // profile name => brokers
+	}{
+		{
+			name: "simple include",
+			files: map[string]string{
+				"/etc/rpk/rpk.yaml": `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [foo:9092]
+includes: [shared/rpk.yaml]
+`,
+				"/etc/rpk/shared/rpk.yaml": `version: 8
+profiles:
+    - name: bar
+      kafka_api:
+        brokers: [bar:9092]
+`,
+			},
+			expKafN: map[string][]string{
+				"foo": {"foo:9092"},
+				"bar": {"bar:9092"},
+			},
+		},
+		{
+			name: "local wins on collision",
+			files: map[string]string{
+				"/etc/rpk/rpk.yaml": `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [local:9092]
+includes: [/shared.yaml]
+`,
+				"/shared.yaml": `version: 8
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [shared:9092]
+`,
+			},
+			expKafN: map[string][]string{
+				"foo": {"local:9092"},
+			},
+		},
+		{
+			name: "self cycle",
+			files: map[string]string{
+				"/etc/rpk/rpk.yaml": `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+includes: [rpk.yaml]
+`,
+			},
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m := make(map[string]testfs.Fmode)
+			for path, content := range test.files {
+				m[path] = testfs.RFile(content)
+			}
+			fs := testfs.FromMap(m)
+			cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			if test.expErr {
+				require.ErrorContains(t, err, "include cycle")
+				return
+			}
+			require.NoError(t, err)
+			y := cfg.VirtualRpkYaml()
+			require.Len(t, y.Profiles, len(test.expKafN))
+			for name, brokers := range test.expKafN {
+				p := y.Profile(name)
+				require.NotNil(t, p, "missing profile %q", name)
+				require.Equal(t, brokers, p.KafkaAPI.Brokers)
+			}
+
+			// Included profiles are never added to the actual file.
+			act, _ := cfg.ActualRpkYaml()
+			require.Len(t, act.Profiles, 1)
+			require.Equal(t, "foo", act.Profiles[0].Name)
+		})
+	}
+}
+
+func TestLoadRpkIncludeSearchPath(t *testing.T) {
+	shared := func(broker string) testfs.Fmode {
+		return testfs.RFile(`version: 8
+profiles:
+    - name: shared
+      kafka_api:
+        brokers: [` + broker + `]
+`)
+	}
+	main := testfs.RFile(`version: 8
+current_profile: foo
+profiles:
+    - name: foo
+includes: [shared.yaml]
+`)
+	for _, test := range []struct {
+		name   string
+		files  map[string]testfs.Fmode
+		dirs   []string
+		env    string
+		exp    string
+		expErr string
+	}{
+		{
+			name: "relative to file wins over search path",
+			files: map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml":    main,
+				"/etc/rpk/shared.yaml": shared("local:9092"),
+				"/org/shared.yaml":     shared("org:9092"),
+			},
+			dirs: []string{"/org"},
+			exp:  "local:9092",
+		},
+		{
+			name: "search path in order",
+			files: map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": main,
+				"/org/shared.yaml":  shared("org:9092"),
+				"/team/shared.yaml": shared("team:9092"),
+			},
+			dirs: []string{"/missing", "/team", "/org"},
+			exp:  "team:9092",
+		},
+		{
+			name: "search path from env",
+			files: map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": main,
+				"/org/shared.yaml":  shared("org:9092"),
+			},
+			env: "/missing" + string(filepath.ListSeparator) + "/org",
+			exp: "org:9092",
+		},
+		{
+			name: "not found",
+			files: map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": main,
+			},
+			dirs:   []string{"/org"},
+			expErr: `unable to find "shared.yaml" included from /etc/rpk/rpk.yaml, searched: /etc/rpk/shared.yaml, /org/shared.yaml`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(EnvConfigDir, test.env)
+			fs := testfs.FromMap(test.files)
+			cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml", IncludeDirs: test.dirs}).Load(fs)
+			if test.expErr != "" {
+				require.EqualError(t, err, test.expErr)
+				return
+			}
+			require.NoError(t, err)
+			p := cfg.VirtualRpkYaml().Profile("shared")
+			require.NotNil(t, p)
+			require.Equal(t, []string{test.exp}, p.KafkaAPI.Brokers)
+		})
+	}
+}
+
+func TestLoadNewerRpkYamlVersion(t *testing.T) {
+	future := fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+`, currentRpkYAMLVersion+1)
+	for _, test := range []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "config",
+			files: map[string]string{"/etc/rpk/rpk.yaml": future},
+		},
+		{
+			name: "include",
+			files: map[string]string{
+				"/etc/rpk/rpk.yaml": fmt.Sprintf(`version: %d
+includes: [future.yaml]
+`, currentRpkYAMLVersion),
+				"/etc/rpk/future.yaml": future,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m := make(map[string]testfs.Fmode)
+			for path, content := range test.files {
+				m[path] = testfs.RFile(content)
+			}
+			_, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(testfs.FromMap(m))
+			require.ErrorContains(t, err, "please upgrade rpk")
+		})
+	}
+}
+
+func TestLoadResolvesSASLPassword(t *testing.T) {
+	t.Setenv("RPK_TEST_SASL_PASSWORD", "from-env")
+	for _, test := range []struct {
+		name     string
+		password string
+		flags    []string
+		exp      string
+		expErr   bool
+	}{
+		{name: "literal", password: "hunter2", exp: "hunter2"},
+		{name: "file", password: "file:/secret", exp: "from-file"},
+		{name: "env", password: "env:RPK_TEST_SASL_PASSWORD", exp: "from-env"},
+		{name: "escaped", password: "literal:file:/secret", exp: "file:/secret"},
+		{name: "from flag", password: "hunter2", flags: []string{"pass=file:/secret"}, exp: "from-file"},
+		{name: "missing file", password: "file:/missing", expErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/secret": testfs.RFile("from-file\n"),
+				"/rpk.yaml": testfs.RFile(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        sasl:
+            user: user
+            password: %s
+            mechanism: PLAIN
+`, currentRpkYAMLVersion, test.password)),
+			})
+			cfg, err := (&Params{ConfigFlag: "/rpk.yaml", FlagOverrides: test.flags}).Load(fs)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, cfg.VirtualProfile().KafkaAPI.SASL.Password)
+			require.Equal(t, test.password, cfg.ActualProfile().KafkaAPI.SASL.Password)
+		})
+	}
+}
+
+func TestLoadBrokerSASL(t *testing.T) {
+	t.Setenv("RPK_TEST_BROKER_PASSWORD", "broker-pass")
+	load := func(t *testing.T, brokerSASL string) (*Config, error) {
+		fs := testfs.FromMap(map[string]testfs.Fmode{
+			"/rpk.yaml": testfs.RFile(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [k0:9092, k1:9092]
+        sasl:
+            user: user
+            password: pass
+            mechanism: scram-sha-512
+        broker_sasl:
+%s`, currentRpkYAMLVersion, brokerSASL)),
+		})
+		return (&Params{ConfigFlag: "/rpk.yaml"}).Load(fs)
+	}
+
+	cfg, err := load(t, `            K1:
+                user: migrated
+                password: env:RPK_TEST_BROKER_PASSWORD
+`)
+	require.NoError(t, err)
+	k := &cfg.VirtualProfile().KafkaAPI
+
+	// A broker without an override uses the profile's SASL.
+	require.Equal(t, &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-512"}, k.SASLFor("k0:9092"))
+
+	// A broker with an override uses its own credentials with the
+	// profile's mechanism, regardless of how its address is spelled.
+	exp := &SASL{User: "migrated", Password: "broker-pass", Mechanism: "SCRAM-SHA-512"}
+	require.Equal(t, exp, k.SASLFor("k1:9092"))
+	require.Equal(t, exp, k.SASLFor("k1"))
+	require.Empty(t, k.BrokerSASL["K1"].Mechanism, "the override itself is not modified")
+
+	// The secret reference is kept in the actual profile.
+	require.Equal(t, "env:RPK_TEST_BROKER_PASSWORD", cfg.ActualProfile().KafkaAPI.BrokerSASL["K1"].Password)
+	act, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	require.Contains(t, act.SecretRefs(), SecretRef{Profile: "foo", Broker: "K1", Kind: SecretKindSASLPassword, Present: true})
+
+	// Clones do not share overrides.
+	dup := act.Clone()
+	dup.Profile("foo").KafkaAPI.BrokerSASL["K1"].User = "changed"
+	require.Equal(t, "migrated", cfg.ActualProfile().KafkaAPI.BrokerSASL["K1"].User)
+
+	for _, test := range []struct {
+		name       string
+		brokerSASL string
+		expErr     string
+	}{
+		{
+			name:       "mismatched mechanism",
+			brokerSASL: "            k1:9092: {user: u, password: p, mechanism: plain}\n",
+			expErr:     "mechanism PLAIN does not match the kafka_api.sasl mechanism SCRAM-SHA-512",
+		},
+		{
+			name:       "same broker twice",
+			brokerSASL: "            k1: {user: u, password: p}\n            k1:9092: {user: u, password: p}\n",
+			expErr:     `"k1" and "k1:9092" are the same broker`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := load(t, test.brokerSASL)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expErr)
+		})
+	}
+}
+
+func TestRpkProfileEnviron(t *testing.T) {
+	full := RpkProfile{
+		KafkaAPI: RpkKafkaAPI{
+			Brokers: []string{"k0:9092", "k1:9092"},
+			TLS:     &TLS{TruststoreFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem", InsecureSkipVerify: true},
+			SASL:    &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"},
+		},
+		AdminAPI: RpkAdminAPI{
+			Addresses: []string{"a0:9644"},
+			TLS:       &TLS{TruststoreFile: "admin-ca.pem"},
+		},
+		SR: RpkSchemaRegistryAPI{
+			Addresses: []string{"sr0:8081"},
+		},
+	}
+	require.Equal(t, []string{
+		"RPK_BROKERS=k0:9092,k1:9092",
+		"RPK_TLS_ENABLED=true",
+		"RPK_TLS_INSECURE_SKIP_VERIFY=true",
+		"RPK_TLS_CA=ca.pem",
+		"RPK_TLS_CERT=cert.pem",
+		"RPK_TLS_KEY=key.pem",
+		"RPK_SASL_MECHANISM=SCRAM-SHA-256",
+		"RPK_USER=user",
+		"RPK_PASS=pass",
+		"RPK_ADMIN_HOSTS=a0:9644",
+		"RPK_ADMIN_TLS_ENABLED=true",
+		"RPK_ADMIN_TLS_CA=admin-ca.pem",
+		"RPK_REGISTRY_HOSTS=sr0:8081",
+	}, full.Environ())
+
+	var empty RpkProfile
+	require.Empty(t, empty.Environ())
+
+	// The variables round trip as overrides.
+	for _, kv := range full.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		t.Setenv(k, v)
+	}
+	cfg, err := new(Params).Load(afero.NewMemMapFs())
+	require.NoError(t, err)
+	p := cfg.VirtualProfile()
+	require.Equal(t, full.KafkaAPI, p.KafkaAPI)
+	require.Equal(t, full.AdminAPI, p.AdminAPI)
+	require.Equal(t, full.SR.Addresses, p.SR.Addresses)
+}
+
+func TestLoadValidatesAddrs(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		brokers   string
+		admin     string
+		overrides []string
+		expErr    string
+	}{
+		{name: "valid", brokers: "[localhost, 127.0.0.1:9092, 'broker-0.example.com:29092']", admin: "[localhost:9644, 'https://admin.example.com']"},
+		{name: "bad port", brokers: "[localhost:909a]", expErr: `"localhost:909a"`},
+		{name: "port out of range", brokers: "[localhost:99999]", expErr: "port must be between 1 and 65535"},
+		{name: "accidental scheme", brokers: "['http://broker:9092']", expErr: "must not have a scheme"},
+		{name: "unsupported admin scheme", admin: "['ftp://admin:9644']", expErr: `unsupported scheme "ftp"`},
+		{name: "bad override", overrides: []string{"brokers=localhost,http://broker:9092"}, expErr: "must not have a scheme"},
+		{name: "bad admin override", overrides: []string{"admin.hosts=localhost:0"}, expErr: "port must be between 1 and 65535"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			y := `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+`
+			if test.brokers != "" {
+				y += "      kafka_api:\n        brokers: " + test.brokers + "\n"
+			}
+			if test.admin != "" {
+				y += "      admin_api:\n        addresses: " + test.admin + "\n"
+			}
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: y},
+			})
+			_, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml", FlagOverrides: test.overrides}).Load(fs)
+			if test.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expErr)
+		})
+	}
+}
+
+func TestLoadAdminEndpointsOrder(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      admin_api:
+        addresses: [zed:9644, alpha, 'https://mid.example.com']
+`},
+	})
+	load := func() *Config {
+		cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	cfg := load()
+	require.Equal(t, []string{"zed:9644", "alpha:9644", "https://mid.example.com"}, cfg.VirtualProfile().AdminAPI.Endpoints())
+
+	act, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	act.Profile("foo").Description = "modified"
+	require.NoError(t, act.Write(fs))
+
+	act, ok = load().ActualRpkYaml()
+	require.True(t, ok)
+	require.Equal(t, "modified", act.Profile("foo").Description)
+	require.Equal(t, []string{"zed:9644", "alpha", "https://mid.example.com"}, act.Profile("foo").AdminAPI.Endpoints())
+
+	// Endpoints returns a copy.
+	endpoints := act.Profile("foo").AdminAPI.Endpoints()
+	endpoints[0] = "changed"
+	require.Equal(t, "zed:9644", act.Profile("foo").AdminAPI.Addresses[0])
+}
+
+func TestLoadCloudDefaults(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: defaulted
+cloud_defaults:
+    resource_group: shared
+profiles:
+    - name: defaulted
+      from_cloud: true
+      cloud_cluster:
+        cluster_id: abc
+        auth_org_id: org
+        auth_kind: sso
+    - name: explicit
+      from_cloud: true
+      cloud_cluster:
+        resource_group: mine
+        cluster_id: def
+        auth_org_id: org
+        auth_kind: sso
+    - name: local
+cloud_auth:
+    - name: auth
+      organization: org
+      org_id: org
+      kind: sso
+`},
+	})
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+
+	vir := cfg.VirtualRpkYaml()
+	require.Equal(t, "shared", vir.Profile("defaulted").CloudCluster.ResourceGroup)
+	require.Equal(t, "mine", vir.Profile("explicit").CloudCluster.ResourceGroup)
+	require.Empty(t, vir.Profile("local").CloudCluster.ResourceGroup)
+
+	// The default must not be persisted into the defaulted cluster.
+	act, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	act.Profile("local").Description = "modified"
+	require.NoError(t, act.Write(fs))
+
+	raw, err := afero.ReadFile(fs, "/etc/rpk/rpk.yaml")
+	require.NoError(t, err)
+	var written RpkYaml
+	require.NoError(t, yaml.Unmarshal(raw, &written))
+	require.Equal(t, "modified", written.Profile("local").Description)
+	require.Empty(t, written.Profile("defaulted").CloudCluster.ResourceGroup)
+	require.Equal(t, "mine", written.Profile("explicit").CloudCluster.ResourceGroup)
+	require.Equal(t, "shared", written.CloudDefaults.ResourceGroup)
+}
+
 func TestConfig_parseDevOverrides(t *testing.T) {
 	var c Config
 	defer func() {
@@ -1204,3 +2040,565 @@ func TestXSetDefaultsPaths(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadProfileTimeouts(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		api      string
+		expKafka RpkKafkaAPI
+		expAdm   RpkAdminAPI
+		expErr   bool
+	}{
+		{
+			name: "valid durations",
+			api: `      kafka_api:
+        dial_timeout: 1s
+        request_timeout_overhead: 2m
+      admin_api:
+        dial_timeout: 500ms
+        request_timeout: 30s
+`,
+			expKafka: RpkKafkaAPI{DialTimeout: Duration{time.Second}, RequestTimeoutOverhead: Duration{2 * time.Minute}},
+			expAdm:   RpkAdminAPI{DialTimeout: Duration{500 * time.Millisecond}, RequestTimeout: Duration{30 * time.Second}},
+		},
+		{
+			name: "empty uses default",
+			api: `      kafka_api:
+        dial_timeout: ""
+      admin_api:
+        request_timeout: ""
+`,
+		},
+		{
+			name: "invalid duration",
+			api: `      kafka_api:
+        dial_timeout: soon
+`,
+			expErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+` + test.api},
+			})
+			cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			p := cfg.VirtualProfile()
+			require.Equal(t, test.expKafka.DialTimeout, p.KafkaAPI.DialTimeout)
+			require.Equal(t, test.expKafka.RequestTimeoutOverhead, p.KafkaAPI.RequestTimeoutOverhead)
+			require.Equal(t, test.expAdm.DialTimeout, p.AdminAPI.DialTimeout)
+			require.Equal(t, test.expAdm.RequestTimeout, p.AdminAPI.RequestTimeout)
+		})
+	}
+
+	require.Zero(t, (&RpkAdminAPI{}).ClientTimeout())
+	require.Equal(t, DefaultAdminRequestTimeout+time.Second, (&RpkAdminAPI{DialTimeout: Duration{time.Second}}).ClientTimeout())
+	require.Equal(t, 3*time.Second, (&RpkAdminAPI{RequestTimeout: Duration{3 * time.Second}}).ClientTimeout())
+}
+
+func TestLoadTrimsNames(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: "foo "
+current_cloud_auth_org_id: org-id
+current_cloud_auth_kind: sso
+cloud_auth:
+    - name: " sso\t"
+      organization: org
+      org_id: org-id
+      kind: sso
+profiles:
+    - name: "  foo"
+    - name: bar
+`},
+	})
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	for _, y := range []*RpkYaml{cfg.VirtualRpkYaml(), &cfg.rpkYamlActual} {
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.Equal(t, []string{"bar", "foo"}, y.ProfileNames())
+		require.Equal(t, []string{"sso"}, y.AuthNames())
+	}
+	require.Equal(t, "foo", cfg.VirtualProfile().Name)
+
+	for _, test := range []struct {
+		name   string
+		body   string
+		expErr error
+	}{
+		{
+			name: "profile collision",
+			body: `profiles:
+    - name: foo
+    - name: "foo "
+`,
+			expErr: ErrDuplicateProfile,
+		},
+		{
+			name: "auth collision",
+			body: `cloud_auth:
+    - name: " sso"
+      organization: org
+      org_id: org-id
+      kind: sso
+    - name: sso
+      organization: org
+      org_id: org-id
+      kind: client-credentials
+`,
+			expErr: ErrDuplicateAuth,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: "version: 8\n" + test.body},
+			})
+			_, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			require.True(t, errors.Is(err, test.expErr), "got err %v", err)
+			require.ErrorContains(t, err, "once surrounding whitespace is trimmed")
+
+			_, err = LoadRpkYamlFromReader(strings.NewReader("version: 8\n" + test.body))
+			require.True(t, errors.Is(err, test.expErr), "got err %v", err)
+		})
+	}
+}
+
+func TestLoadMissingSelectedProfile(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+`},
+	})
+	_, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml", Profile: "bar"}).Load(fs)
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+	require.ErrorContains(t, err, `"bar"`)
+}
+
+func TestLoadProfileClientTuning(t *testing.T) {
+	keepAlive := func(b bool) *bool { return &b }
+	defaults := KafkaClientTuning{
+		MaxInFlight:     DefaultKafkaMaxInFlight,
+		ConnIdleTimeout: Duration{DefaultKafkaConnIdleTimeout},
+		KeepAlive:       keepAlive(DefaultKafkaKeepAlive),
+	}
+	for _, test := range []struct {
+		name   string
+		api    string
+		exp    *KafkaClientTuning
+		expDef KafkaClientTuning
+	}{
+		{
+			name:   "absent",
+			expDef: defaults,
+		},
+		{
+			name: "partially set",
+			api: `      kafka_api:
+        client_tuning:
+            max_in_flight: 5
+`,
+			exp: &KafkaClientTuning{MaxInFlight: 5},
+			expDef: KafkaClientTuning{
+				MaxInFlight:     5,
+				ConnIdleTimeout: defaults.ConnIdleTimeout,
+				KeepAlive:       defaults.KeepAlive,
+			},
+		},
+		{
+			name: "fully set",
+			api: `      kafka_api:
+        client_tuning:
+            max_in_flight: 10
+            conn_idle_timeout: 1m
+            keep_alive: false
+`,
+			exp: &KafkaClientTuning{MaxInFlight: 10, ConnIdleTimeout: Duration{time.Minute}, KeepAlive: keepAlive(false)},
+			expDef: KafkaClientTuning{
+				MaxInFlight:     10,
+				ConnIdleTimeout: Duration{time.Minute},
+				KeepAlive:       keepAlive(false),
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+` + test.api},
+			})
+			cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			require.NoError(t, err)
+			tuning := cfg.VirtualProfile().KafkaAPI.ClientTuning
+			require.Equal(t, test.exp, tuning)
+			require.Equal(t, test.expDef, tuning.WithDefaults())
+
+			// Writing and reloading keeps exactly what was set:
+			// defaults are never written.
+			y, ok := cfg.ActualRpkYaml()
+			require.True(t, ok)
+			require.NoError(t, y.Write(fs))
+			cfg, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			require.NoError(t, err)
+			require.Equal(t, test.exp, cfg.VirtualProfile().KafkaAPI.ClientTuning)
+		})
+	}
+}
+
+func TestLoadSASLGSSAPI(t *testing.T) {
+	t.Setenv("KEYTAB_DIR", "/secrets")
+	for _, test := range []struct {
+		name   string
+		gssapi string
+		exp    *SASLGSSAPI
+		expErr string
+	}{
+		{
+			name: "keytab",
+			gssapi: `            gssapi:
+                keytab: kafka.keytab
+                principal: rpk@EXAMPLE.COM
+                service_name: kafka
+                realm: EXAMPLE.COM
+`,
+			exp: &SASLGSSAPI{
+				Keytab:      "/etc/rpk/kafka.keytab",
+				Principal:   "rpk@EXAMPLE.COM",
+				ServiceName: "kafka",
+				Realm:       "EXAMPLE.COM",
+			},
+		},
+		{
+			name: "keytab from env",
+			gssapi: `            gssapi:
+                keytab: $KEYTAB_DIR/kafka.keytab
+`,
+			exp: &SASLGSSAPI{Keytab: "/secrets/kafka.keytab"},
+		},
+		{
+			name: "password",
+			gssapi: `            gssapi:
+                service_name: kafka
+                realm: EXAMPLE.COM
+                username: rpk
+                password: secret
+`,
+			exp: &SASLGSSAPI{ServiceName: "kafka", Realm: "EXAMPLE.COM", Username: "rpk", Password: "secret"},
+		},
+		{
+			name: "neither keytab nor password",
+			gssapi: `            gssapi:
+                username: rpk
+`,
+			expErr: "requires either gssapi.keytab or gssapi.password",
+		},
+		{
+			name:   "no gssapi",
+			expErr: "requires either gssapi.keytab or gssapi.password",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        sasl:
+            mechanism: gssapi
+` + test.gssapi},
+			})
+			cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+			if test.expErr != "" {
+				require.ErrorContains(t, err, test.expErr)
+				return
+			}
+			require.NoError(t, err)
+			sasl := cfg.VirtualProfile().KafkaAPI.SASL
+			require.Equal(t, "GSSAPI", sasl.Mechanism)
+			require.Equal(t, test.exp, sasl.GSSAPI)
+
+			// The keytab is only expanded in the virtual rpk.yaml.
+			act, _ := cfg.ActualRpkYaml()
+			require.NotEqual(t, "/etc/rpk/kafka.keytab", act.Profile("foo").KafkaAPI.SASL.GSSAPI.Keytab)
+		})
+	}
+}
+
+func TestLoadDebugLogs(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+current_cloud_auth_org_id: org
+current_cloud_auth_kind: sso
+cloud_auth:
+    - name: auth
+      organization: org
+      org_id: org
+      kind: sso
+      auth_token: super-secret-token
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [broker-0.example.com:9092]
+        sasl:
+            user: user
+            password: super-secret-password
+`},
+	})
+	t.Setenv("RPK_USER", "envuser")
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	p := &Params{
+		ConfigFlag:    "/etc/rpk/rpk.yaml",
+		FlagOverrides: []string{"pass=another-secret"},
+	}
+	p.loggerOnce.Do(func() { p.logger = zap.New(core) })
+	_, err := p.Load(fs)
+	require.NoError(t, err)
+
+	byMsg := make(map[string][]map[string]interface{})
+	for _, e := range logs.All() {
+		byMsg[e.Message] = append(byMsg[e.Message], e.ContextMap())
+		for _, v := range e.ContextMap() {
+			for _, secret := range []string{"super-secret-token", "super-secret-password", "another-secret"} {
+				require.NotContains(t, fmt.Sprint(v), secret, "secret logged in %q", e.Message)
+			}
+		}
+	}
+	require.Equal(t, []map[string]interface{}{{"path": "/etc/rpk/rpk.yaml"}}, byMsg["loading rpk.yaml"])
+	require.Equal(t, []map[string]interface{}{
+		{"from": "env", "key": "user"},
+		{"from": "flag", "key": "pass"},
+	}, byMsg["applied config override"])
+	require.Len(t, byMsg["using rpk profile"], 1)
+	require.Equal(t, "foo", byMsg["using rpk profile"][0]["profile"])
+	require.Equal(t, []interface{}{"broker-0.example.com:9092"}, byMsg["using rpk profile"][0]["brokers"])
+	require.Equal(t, []map[string]interface{}{{"auth": "auth", "org_id": "org", "kind": "sso"}}, byMsg["using cloud auth"])
+}
+
+func TestLoadInsecureProfile(t *testing.T) {
+	load := func(t *testing.T, insecure bool) (*Config, *observer.ObservedLogs) {
+		fs := testfs.FromMap(map[string]testfs.Fmode{
+			"/etc/rpk/rpk.yaml": testfs.RFile(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      insecure: %t
+      kafka_api:
+        brokers: [broker-0.example.com:9092]
+        tls: {}
+      admin_api:
+        addresses: [broker-0.example.com:9644]
+        tls: {}
+      schema_registry:
+        addresses: [broker-0.example.com:8081]
+`, currentRpkYAMLVersion, insecure)),
+		})
+		core, logs := observer.New(zapcore.DebugLevel)
+		p := &Params{ConfigFlag: "/etc/rpk/rpk.yaml"}
+		p.loggerOnce.Do(func() { p.logger = zap.New(core) })
+		cfg, err := p.Load(fs)
+		require.NoError(t, err)
+		return cfg, logs
+	}
+	tlsConfigs := func(t *testing.T, p *RpkProfile) (kafka, admin *tls.Config) {
+		kafka, err := p.KafkaAPI.TLS.Config(afero.NewMemMapFs())
+		require.NoError(t, err)
+		admin, err = p.AdminAPI.TLS.Config(afero.NewMemMapFs())
+		require.NoError(t, err)
+		return kafka, admin
+	}
+	const warning = "TLS verification is disabled for the Kafka and Admin APIs because the profile is marked insecure"
+
+	cfg, logs := load(t, true)
+	kafka, admin := tlsConfigs(t, cfg.VirtualProfile())
+	require.True(t, kafka.InsecureSkipVerify)
+	require.True(t, admin.InsecureSkipVerify)
+	require.Nil(t, cfg.VirtualProfile().SR.TLS, "insecure does not enable TLS")
+	warnings := logs.FilterMessage(warning).All()
+	require.Len(t, warnings, 1)
+	require.Equal(t, zapcore.WarnLevel, warnings[0].Level)
+	require.Equal(t, map[string]interface{}{"profile": "foo"}, warnings[0].ContextMap())
+
+	// Only the virtual profile skips verification: writing the actual
+	// rpk.yaml keeps the toggle, not per-endpoint settings.
+	require.False(t, cfg.ActualProfile().KafkaAPI.TLS.InsecureSkipVerify)
+	require.False(t, cfg.ActualProfile().AdminAPI.TLS.InsecureSkipVerify)
+
+	cfg, logs = load(t, false)
+	kafka, admin = tlsConfigs(t, cfg.VirtualProfile())
+	require.False(t, kafka.InsecureSkipVerify)
+	require.False(t, admin.InsecureSkipVerify)
+	require.Empty(t, logs.FilterMessage(warning).All())
+}
+
+func TestLoadYamlAnchors(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [foo:9092]
+        sasl: &sasl
+            user: user
+            password: pass
+            mechanism: SCRAM-SHA-256
+    - name: bar
+      kafka_api:
+        brokers: [bar:9092]
+        sasl: *sasl
+    - name: biz
+      kafka_api:
+        brokers: [biz:9092]
+        sasl:
+            <<: *sasl
+            user: other
+`},
+	})
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	act, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+
+	for _, y := range []*RpkYaml{cfg.VirtualRpkYaml(), act} {
+		foo, bar, biz := y.Profile("foo").KafkaAPI.SASL, y.Profile("bar").KafkaAPI.SASL, y.Profile("biz").KafkaAPI.SASL
+		require.Equal(t, &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"}, bar)
+		require.Equal(t, &SASL{User: "other", Password: "pass", Mechanism: "SCRAM-SHA-256"}, biz)
+
+		// Each profile has its own copy of the anchored block.
+		foo.Password = "changed"
+		require.Equal(t, "pass", bar.Password)
+		require.Equal(t, "pass", biz.Password)
+	}
+}
+
+func TestLoadImplicitProfile(t *testing.T) {
+	newFs := func(profiles string) afero.Fs {
+		return testfs.FromMap(map[string]testfs.Fmode{
+			"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: "version: 8\nprofiles:\n" + profiles},
+		})
+	}
+	load := func(t *testing.T, profiles string) *Config {
+		cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(newFs(profiles))
+		require.NoError(t, err)
+		return cfg
+	}
+
+	t.Run("single profile", func(t *testing.T) {
+		cfg := load(t, `    - name: only
+      kafka_api:
+        brokers: [only:9092]
+`)
+		require.Equal(t, "only", cfg.VirtualProfile().Name)
+		require.Equal(t, []string{"only:9092"}, cfg.VirtualProfile().KafkaAPI.Brokers)
+
+		// The implicit selection is not persisted.
+		act, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		require.Equal(t, "", act.CurrentProfile)
+		p, err := act.ResolveCurrentProfile()
+		require.NoError(t, err)
+		require.Equal(t, "only", p.Name)
+
+		p, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).LoadVirtualProfile(newFs("    - name: only\n"))
+		require.NoError(t, err)
+		require.Equal(t, "only", p.Name)
+	})
+
+	t.Run("multiple profiles", func(t *testing.T) {
+		cfg := load(t, `    - name: foo
+    - name: bar
+`)
+		act, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		_, err := act.ResolveCurrentProfile()
+		require.True(t, errors.Is(err, ErrNoCurrentProfile), "got err %v", err)
+		require.Contains(t, err.Error(), "bar, foo")
+
+		// Commands that use the profile fail rather than silently
+		// using the default profile, unless a profile is selected.
+		fs := newFs("    - name: foo\n    - name: bar\n")
+		_, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).LoadVirtualProfile(fs)
+		require.True(t, errors.Is(err, ErrNoCurrentProfile), "got err %v", err)
+		require.Contains(t, err.Error(), "bar, foo")
+		p, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml", Profile: "bar"}).LoadVirtualProfile(fs)
+		require.NoError(t, err)
+		require.Equal(t, "bar", p.Name)
+	})
+}
+
+func TestRpkProfileEffectiveKafkaAPI(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [file:9092]
+        request_timeout_overhead: 7s
+        sasl:
+            user: bob
+            password: secret
+globals:
+    dial_timeout: 2s
+`},
+	})
+	cfg, err := (&Params{
+		ConfigFlag:    "/etc/rpk/rpk.yaml",
+		FlagOverrides: []string{"brokers=flag:9092"},
+	}).Load(fs)
+	require.NoError(t, err)
+	p := cfg.VirtualProfile()
+	before := p.KafkaAPI
+
+	k := p.EffectiveKafkaAPI()
+	def := (*KafkaClientTuning)(nil).WithDefaults()
+	require.Equal(t, RpkKafkaAPI{
+		Brokers:                []string{"flag:9092"},
+		DialTimeout:            Duration{2 * time.Second}, // from globals
+		RequestTimeoutOverhead: Duration{7 * time.Second}, // from the profile
+		ClientTuning:           &def,
+		SASL:                   &SASL{User: "bob", Password: "secret", Mechanism: "SCRAM-SHA-256"},
+	}, k)
+
+	// The stored profile is untouched, and the returned copy does not
+	// alias it.
+	require.Equal(t, before, p.KafkaAPI)
+	require.Nil(t, p.KafkaAPI.ClientTuning)
+	require.Equal(t, "", p.KafkaAPI.SASL.Mechanism)
+	k.SASL.User = "alice"
+	require.Equal(t, "bob", p.KafkaAPI.SASL.User)
+
+	// Without any brokers, we default to localhost unless
+	// no_default_cluster is set.
+	empty := RpkProfile{}
+	k = empty.EffectiveKafkaAPI()
+	require.Equal(t, []string{"127.0.0.1:9092"}, k.Brokers)
+	require.Equal(t, Duration{DefaultKafkaDialTimeout}, k.DialTimeout)
+	require.Equal(t, Duration{DefaultKafkaRequestTimeoutOverhead}, k.RequestTimeoutOverhead)
+	require.Nil(t, k.SASL)
+	require.Equal(t, RpkKafkaAPI{}, empty.KafkaAPI)
+
+	y, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+	y.Globals.NoDefaultCluster = true
+	require.NoError(t, y.Write(fs))
+	cfg, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	p = cfg.VirtualProfile()
+	p.KafkaAPI.Brokers = nil
+	require.Empty(t, p.EffectiveKafkaAPI().Brokers)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetPath sets the value at path in the rpk.yaml. The path is a dotted list
+// of yaml field names, and slice elements can be selected either with a
+// bracketed index or with a numeric path element:
+//
+//	profiles[0].kafka_api.brokers[0]
+//	profiles.0.kafka_api.brokers.0
+//
+// The value is decoded as yaml into the field, so numbers, booleans, and
+// durations are coerced to the field's type, and a slice can be set with
+// either a yaml list or a comma separated list. Selecting the index one past
+// the end of a slice appends to it. This is the same decoding used in 'rpk
+// profile set'.
+func (y *RpkYaml) SetPath(path, value string) error {
+	key, err := normalizePath(path)
+	if err != nil {
+		return err
+	}
+	if err := Set(y, key, value); err != nil {
+		return fmt.Errorf("unable to set %q: %w", path, err)
+	}
+	return nil
+}
+
+// GetPath returns the value at path in the rpk.yaml; see SetPath for the path
+// format. Strings are returned as is, and all other values are returned as
+// yaml. Unlike SetPath, GetPath never modifies the rpk.yaml: indexing past the
+// end of a slice is an error, and unset optional fields return "null".
+func (y *RpkYaml) GetPath(path string) (string, error) {
+	key, err := normalizePath(path)
+	if err != nil {
+		return "", err
+	}
+	v := reflect.ValueOf(y).Elem()
+	for _, tag := range strings.Split(key, ".") {
+		name, index, err := splitTagIndex(tag)
+		if err != nil {
+			return "", err
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "null", nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return "", fmt.Errorf("unable to get %q: field %q is not an object", path, name)
+		}
+		field, _, err := getFieldByTag(name, v)
+		if err != nil || !field.IsValid() {
+			return "", fmt.Errorf("unable to get %q: unable to find field %q", path, name)
+		}
+		v = field
+		if index >= 0 {
+			if v.Kind() != reflect.Slice {
+				return "", fmt.Errorf("unable to get %q: field %q is not a list", path, name)
+			}
+			if index >= v.Len() {
+				return "", fmt.Errorf("unable to get %q: index %d out of range of %d elements in %q", path, index, v.Len(), name)
+			}
+			v = v.Index(index)
+		}
+	}
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	raw, err := yaml.Marshal(v.Interface())
+	if err != nil {
+		return "", fmt.Errorf("unable to get %q: %v", path, err)
+	}
+	return strings.TrimSuffix(string(raw), "\n"), nil
+}
+
+// normalizePath converts numeric path elements into bracketed indices on the
+// previous element, i.e. "profiles.0.name" becomes "profiles[0].name", which
+// is the format Set understands.
+func normalizePath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path must not be empty")
+	}
+	var tags []string
+	for _, elem := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(elem); err != nil {
+			tags = append(tags, elem)
+			continue
+		}
+		if len(tags) == 0 || strings.HasSuffix(tags[len(tags)-1], "]") {
+			return "", fmt.Errorf("invalid path %q: index %s does not follow a field", path, elem)
+		}
+		tags[len(tags)-1] += "[" + elem + "]"
+	}
+	for _, tag := range tags {
+		if _, _, err := splitTagIndex(tag); err != nil {
+			return "", fmt.Errorf("invalid path %q: %v", path, err)
+		}
+	}
+	return strings.Join(tags, "."), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkYamlSetGetPath(t *testing.T) {
+	mk := func() RpkYaml {
+		return RpkYaml{
+			Version: currentRpkYAMLVersion,
+			Profiles: []RpkProfile{
+				{Name: "foo", KafkaAPI: RpkKafkaAPI{Brokers: []string{"a:9092", "b:9092"}}},
+				{Name: "bar"},
+			},
+		}
+	}
+
+	t.Run("scalar", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.SetPath("current_profile", "bar"))
+		require.NoError(t, y.SetPath("globals.dial_timeout", "5s"))
+		require.NoError(t, y.SetPath("profiles.1.kafka_api.client_tuning.max_in_flight", "3"))
+		require.Equal(t, "bar", y.CurrentProfile)
+		require.Equal(t, 5*time.Second, y.Globals.DialTimeout.Duration)
+		require.Equal(t, 3, y.Profiles[1].KafkaAPI.ClientTuning.MaxInFlight)
+
+		for path, exp := range map[string]string{
+			"current_profile":      "bar",
+			"globals.dial_timeout": "5s",
+			"profiles[1].kafka_api.client_tuning.max_in_flight": "3",
+			"profiles.1.kafka_api.client_tuning.max_in_flight":  "3",
+			"profiles.0.kafka_api.sasl":                         "null",
+		} {
+			got, err := y.GetPath(path)
+			require.NoError(t, err, "path %q", path)
+			require.Equal(t, exp, got, "path %q", path)
+		}
+
+		require.ErrorContains(t, y.SetPath("globals.dial_timeout", "soon"), `unable to set "globals.dial_timeout"`)
+		require.ErrorContains(t, y.SetPath("profiles.0.read_only", "maybe"), `unable to set "profiles.0.read_only"`)
+	})
+
+	t.Run("slice element", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.SetPath("profiles.0.kafka_api.brokers.1", "host:9092"))
+		require.NoError(t, y.SetPath("profiles[1].kafka_api.brokers[0]", "other:9092"))
+		require.Equal(t, []string{"a:9092", "host:9092"}, y.Profiles[0].KafkaAPI.Brokers)
+		require.Equal(t, []string{"other:9092"}, y.Profiles[1].KafkaAPI.Brokers)
+
+		got, err := y.GetPath("profiles.0.kafka_api.brokers.1")
+		require.NoError(t, err)
+		require.Equal(t, "host:9092", got)
+		got, err = y.GetPath("profiles.0.kafka_api.brokers")
+		require.NoError(t, err)
+		require.Equal(t, "- a:9092\n- host:9092", got)
+
+		// Setting a whole slice replaces it.
+		require.NoError(t, y.SetPath("profiles.0.kafka_api.brokers", "c:9092,d:9092"))
+		require.Equal(t, []string{"c:9092", "d:9092"}, y.Profiles[0].KafkaAPI.Brokers)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		y := mk()
+		for _, path := range []string{
+			"",
+			"0.name",
+			"profiles.0.1",
+			"profiles.-1.name",
+			"profiles.0.nope",
+			"no.such.field",
+		} {
+			require.Error(t, y.SetPath(path, "x"), "path %q", path)
+			_, err := y.GetPath(path)
+			require.Error(t, err, "path %q", path)
+		}
+
+		_, err := y.GetPath("profiles.5.name")
+		require.ErrorContains(t, err, "out of range")
+		_, err = y.GetPath("current_profile.name")
+		require.ErrorContains(t, err, "not an object")
+		_, err = y.GetPath("current_profile[0]")
+		require.ErrorContains(t, err, "not a list")
+
+		// Failed sets and gets leave the rpk.yaml unchanged.
+		require.Equal(t, mk(), y)
+	})
+}
@@ -11,12 +11,17 @@ package config
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"path"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/twmb/tlscfg"
+	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 
 	rpkos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
@@ -128,6 +133,7 @@ type (
 		CertFile           string `yaml:"cert_file,omitempty" json:"cert_file,omitempty"`
 		TruststoreFile     string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
 		InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+		ServerName         string `yaml:"server_name,omitempty" json:"server_name,omitempty"`
 	}
 
 	ServerTLS struct {
@@ -179,11 +185,52 @@ type (
 		Brokers []string `yaml:"brokers,omitempty" json:"brokers,omitempty"`
 		TLS     *TLS     `yaml:"tls,omitempty" json:"tls,omitempty"`
 		SASL    *SASL    `yaml:"sasl,omitempty" json:"sasl,omitempty"`
+
+		// BrokerSASL overrides SASL for individual brokers, keyed by
+		// the address rpk dials: the seed address for seed brokers,
+		// and the address advertised in metadata for all others. The
+		// mechanism is negotiated before the broker is known, so
+		// overrides can only change credentials: an override's
+		// mechanism must be empty or match SASL's. SASL is used for
+		// every broker once any broker uses it, so if SASL is unset,
+		// every broker needs an override.
+		BrokerSASL map[string]*SASL `yaml:"broker_sasl,omitempty" json:"broker_sasl,omitempty"`
+
+		// DialTimeout and RequestTimeoutOverhead, if non-zero,
+		// override the globals dial_timeout and
+		// request_timeout_overhead for this profile. As with the
+		// global, the overhead is added to the timeout of requests
+		// that have one; it is not a deadline for the whole request.
+		DialTimeout            Duration `yaml:"dial_timeout,omitempty" json:"dial_timeout,omitempty"`
+		RequestTimeoutOverhead Duration `yaml:"request_timeout_overhead,omitempty" json:"request_timeout_overhead,omitempty"`
+
+		ClientTuning *KafkaClientTuning `yaml:"client_tuning,omitempty" json:"client_tuning,omitempty"`
+	}
+
+	// KafkaClientTuning tunes how the Kafka client manages connections
+	// and requests. Unset fields use the client defaults; see
+	// WithDefaults.
+	KafkaClientTuning struct {
+		// MaxInFlight is the maximum number of produce requests in
+		// flight per broker. This only applies if idempotency is
+		// disabled, i.e. when producing with acks 0 or 1.
+		MaxInFlight int `yaml:"max_in_flight,omitempty" json:"max_in_flight,omitempty"`
+		// ConnIdleTimeout is how long a connection may idle before it
+		// is closed.
+		ConnIdleTimeout Duration `yaml:"conn_idle_timeout,omitempty" json:"conn_idle_timeout,omitempty"`
+		// KeepAlive is whether TCP keep-alives are sent on broker
+		// connections.
+		KeepAlive *bool `yaml:"keep_alive,omitempty" json:"keep_alive,omitempty"`
 	}
 
 	RpkAdminAPI struct {
 		Addresses []string `yaml:"addresses,omitempty" json:"addresses,omitempty"`
 		TLS       *TLS     `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+		// DialTimeout and RequestTimeout, if non-zero, bound admin API
+		// requests; see ClientTimeout.
+		DialTimeout    Duration `yaml:"dial_timeout,omitempty" json:"dial_timeout,omitempty"`
+		RequestTimeout Duration `yaml:"request_timeout,omitempty" json:"request_timeout,omitempty"`
 	}
 
 	RpkSchemaRegistryAPI struct {
@@ -192,12 +239,195 @@ type (
 	}
 
 	SASL struct {
-		User      string `yaml:"user,omitempty" json:"user,omitempty"`
-		Password  string `yaml:"password,omitempty" json:"password,omitempty"`
-		Mechanism string `yaml:"mechanism,omitempty" json:"mechanism,omitempty"`
+		User      string      `yaml:"user,omitempty" json:"user,omitempty"`
+		Password  string      `yaml:"password,omitempty" json:"password,omitempty"`
+		Mechanism string      `yaml:"mechanism,omitempty" json:"mechanism,omitempty"`
+		OAuth     *SASLOAuth  `yaml:"oauth,omitempty" json:"oauth,omitempty"`
+		GSSAPI    *SASLGSSAPI `yaml:"gssapi,omitempty" json:"gssapi,omitempty"`
+	}
+
+	// SASLGSSAPI configures Kerberos authentication for the GSSAPI
+	// mechanism. Either Keytab or Password must be set. The settings
+	// are validated when loading, but rpk's Kafka client rejects GSSAPI
+	// until it can authenticate with Kerberos.
+	SASLGSSAPI struct {
+		// Keytab is the path to a keytab file used to authenticate as
+		// Principal. Relative paths are resolved against the
+		// directory of the rpk.yaml.
+		Keytab    string `yaml:"keytab,omitempty" json:"keytab,omitempty"`
+		Principal string `yaml:"principal,omitempty" json:"principal,omitempty"`
+		// ServiceName is the Kerberos service name of the brokers,
+		// which is usually "kafka".
+		ServiceName string `yaml:"service_name,omitempty" json:"service_name,omitempty"`
+		Realm       string `yaml:"realm,omitempty" json:"realm,omitempty"`
+		// Username and Password authenticate with a password rather
+		// than a keytab.
+		Username string `yaml:"username,omitempty" json:"username,omitempty"`
+		Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	}
+
+	// SASLOAuth configures how rpk obtains tokens for the OAUTHBEARER
+	// mechanism.
+	SASLOAuth struct {
+		// TokenCommand is a shell command, run with /bin/sh, or cmd on
+		// Windows, that prints a token to stdout. The command is run
+		// again to refresh the token once it expires.
+		TokenCommand string `yaml:"token_command,omitempty" json:"token_command,omitempty"`
+		// Timeout bounds how long TokenCommand may run, defaulting to
+		// DefaultTokenCommandTimeout.
+		Timeout Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	}
 )
 
+// saslMechanisms are the SASL mechanisms that may be specified in
+// kafka_api.sasl.mechanism. CLOUD-OIDC is rpk specific and uses the current
+// cloud auth token.
+var saslMechanisms = []string{
+	"SCRAM-SHA-256",
+	"SCRAM-SHA-512",
+	"PLAIN",
+	"OAUTHBEARER",
+	"GSSAPI",
+	"CLOUD-OIDC",
+}
+
+// normalizeMechanism trims and uppercases the SASL mechanism, and returns an
+// error if the mechanism is not a known mechanism, or if the mechanism is
+// GSSAPI and neither a keytab nor a password is configured. An empty
+// mechanism is valid and means SCRAM-SHA-256. This is ok to call even if s is
+// nil.
+func (s *SASL) normalizeMechanism() error {
+	if s == nil {
+		return nil
+	}
+	s.Mechanism = strings.ToUpper(strings.TrimSpace(s.Mechanism))
+	if s.Mechanism == "" {
+		return nil
+	}
+	for _, m := range saslMechanisms {
+		if s.Mechanism == m {
+			if m == "GSSAPI" && (s.GSSAPI == nil || s.GSSAPI.Keytab == "" && s.GSSAPI.Password == "") {
+				return errors.New("the GSSAPI mechanism requires either gssapi.keytab or gssapi.password")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid SASL mechanism %q, valid mechanisms are: %s", s.Mechanism, strings.Join(saslMechanisms, ", "))
+}
+
+// SASLFor returns the SASL configuration for connections to broker: the
+// broker's override in BrokerSASL if there is one, and SASL otherwise.
+// Addresses are compared as in ProfileForBroker, so "localhost" matches an
+// override for "localhost:9092". An override without a mechanism uses SASL's
+// mechanism; overrides are returned as a copy.
+func (k *RpkKafkaAPI) SASLFor(broker string) *SASL {
+	if want, ok := normalizeBrokerAddr(broker); ok {
+		for addr, s := range k.BrokerSASL {
+			if have, ok := normalizeBrokerAddr(addr); ok && have == want && s != nil {
+				dup := *s
+				if dup.Mechanism == "" && k.SASL != nil {
+					dup.Mechanism = k.SASL.Mechanism
+				}
+				return &dup
+			}
+		}
+	}
+	return k.SASL
+}
+
+// normalizeBrokerSASL normalizes the mechanism of every broker override, and
+// ensures that overrides can be used: each address must be valid and unique,
+// and each mechanism must match SASL's, which defaults to SCRAM-SHA-256.
+func (k *RpkKafkaAPI) normalizeBrokerSASL() error {
+	mechanism := func(s *SASL) string {
+		if s == nil || s.Mechanism == "" {
+			return "SCRAM-SHA-256"
+		}
+		return s.Mechanism
+	}
+	addrs := maps.Keys(k.BrokerSASL)
+	sort.Strings(addrs)
+	seen := make(map[string]string)
+	for _, addr := range addrs {
+		norm, ok := normalizeBrokerAddr(addr)
+		if !ok {
+			return fmt.Errorf("broker_sasl: invalid broker address %q", addr)
+		}
+		if prior, ok := seen[norm]; ok {
+			return fmt.Errorf("broker_sasl: %q and %q are the same broker", prior, addr)
+		}
+		seen[norm] = addr
+
+		s := k.BrokerSASL[addr]
+		if err := s.normalizeMechanism(); err != nil {
+			return fmt.Errorf("broker_sasl %q: %w", addr, err)
+		}
+		if s == nil || s.Mechanism == "" {
+			continue
+		}
+		if m := mechanism(k.SASL); s.Mechanism != m {
+			return fmt.Errorf("broker_sasl %q: mechanism %s does not match the kafka_api.sasl mechanism %s, broker overrides can only change credentials", addr, s.Mechanism, m)
+		}
+	}
+	return nil
+}
+
+// Endpoints returns the admin API addresses in priority order, which is the
+// order they are listed in the config: addresses are never reordered when
+// loading or writing the config. Index based host selection, such as
+// '--host 0', refers to this order. The returned slice is a copy.
+func (a *RpkAdminAPI) Endpoints() []string {
+	return append([]string(nil), a.Addresses...)
+}
+
+// DefaultAdminRequestTimeout is the admin API client timeout used if
+// admin_api.request_timeout is unset.
+const DefaultAdminRequestTimeout = 10 * time.Second
+
+// ClientTimeout returns the overall timeout for an admin API request, or zero
+// if neither timeout is set and the client default should be used. The admin
+// API client does not expose its dialer, so the dial timeout is added on top
+// of the request timeout rather than applied separately.
+func (a *RpkAdminAPI) ClientTimeout() time.Duration {
+	if a.DialTimeout.Duration == 0 && a.RequestTimeout.Duration == 0 {
+		return 0
+	}
+	req := a.RequestTimeout.Duration
+	if req == 0 {
+		req = DefaultAdminRequestTimeout
+	}
+	return a.DialTimeout.Duration + req
+}
+
+// Defaults for unset KafkaClientTuning fields, which match the Kafka client
+// defaults.
+const (
+	DefaultKafkaMaxInFlight     = 1
+	DefaultKafkaConnIdleTimeout = 20 * time.Second
+	DefaultKafkaKeepAlive       = true
+)
+
+// WithDefaults returns a copy of the tuning with every unset field set to its
+// default. This is ok to call even if t is nil.
+func (t *KafkaClientTuning) WithDefaults() KafkaClientTuning {
+	var r KafkaClientTuning
+	if t != nil {
+		r = *t
+	}
+	if r.MaxInFlight <= 0 {
+		r.MaxInFlight = DefaultKafkaMaxInFlight
+	}
+	if r.ConnIdleTimeout.Duration <= 0 {
+		r.ConnIdleTimeout.Duration = DefaultKafkaConnIdleTimeout
+	}
+	keepAlive := DefaultKafkaKeepAlive
+	if r.KeepAlive != nil {
+		keepAlive = *r.KeepAlive
+	}
+	r.KeepAlive = &keepAlive
+	return r
+}
+
 func (t *TLS) Config(fs afero.Fs) (*tls.Config, error) {
 	if t == nil {
 		return nil, nil
@@ -221,6 +451,7 @@ func (t *TLS) Config(fs afero.Fs) (*tls.Config, error) {
 		return nil, err
 	}
 	tc.InsecureSkipVerify = t.InsecureSkipVerify
+	tc.ServerName = t.ServerName
 	return tc, nil
 }
 
@@ -10,24 +10,74 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cloudapi"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/httpapi"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/publicapi"
 	"github.com/spf13/afero"
 	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 
+	rpknet "github.com/redpanda-data/redpanda/src/go/rpk/pkg/net"
 	rpkos "github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
 )
 
+var (
+	// ErrProfileNotFound is returned when a named profile does not exist.
+	ErrProfileNotFound = errors.New("profile does not exist")
+	// ErrDuplicateProfile is returned when a profile name is already in
+	// use.
+	ErrDuplicateProfile = errors.New("profile already exists")
+	// ErrAuthNotFound is returned when a named cloud auth does not exist.
+	ErrAuthNotFound = errors.New("cloud auth does not exist")
+	// ErrDuplicateAuth is returned when a cloud auth name is already in
+	// use.
+	ErrDuplicateAuth = errors.New("cloud auth already exists")
+	// ErrAuthInUse is returned when deleting a cloud auth that profiles
+	// still reference.
+	ErrAuthInUse = errors.New("cloud auth is in use")
+	// ErrUnknownTokenExpiry is returned from RpkCloudAuth.Expired if the
+	// auth token is not a JWT or does not have an expiry.
+	ErrUnknownTokenExpiry = errors.New("unable to determine cloud auth token expiry")
+	// ErrEnvironmentNotFound is returned when a named environment does
+	// not exist.
+	ErrEnvironmentNotFound = errors.New("environment does not exist")
+	// ErrNoCurrentProfile is returned when no current profile is selected.
+	ErrNoCurrentProfile = errors.New("no current profile is selected")
+	// ErrNoCurrentAuth is returned when no current cloud auth is selected.
+	ErrNoCurrentAuth = errors.New("no current cloud auth is selected")
+)
+
+// EnvRpkYamlPath is the environment variable that, if set, overrides the
+// default rpk.yaml path.
+const EnvRpkYamlPath = "RPK_YAML_PATH"
+
 // DefaultRpkYamlPath returns the OS equivalent of ~/.config/rpk/rpk.yaml, if
-// $HOME is defined. The returned path is an absolute path.
+// $HOME is defined, or the path in $RPK_YAML_PATH if set. The returned path
+// is an absolute path.
 func DefaultRpkYamlPath() (string, error) {
+	if path := os.Getenv(EnvRpkYamlPath); path != "" {
+		return filepath.Abs(path)
+	}
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", errors.New("unable to load the user config directory -- is $HOME unset?")
@@ -39,7 +89,7 @@ func defaultVirtualRpkYaml() (RpkYaml, error) {
 	path, _ := DefaultRpkYamlPath() // if err is non-nil, we fail in Write
 	y := RpkYaml{
 		fileLocation: path,
-		Version:      5,
+		Version:      currentRpkYAMLVersion,
 		Profiles:     []RpkProfile{DefaultRpkProfile()},
 		CloudAuths:   []RpkCloudAuth{DefaultRpkCloudAuth()},
 	}
@@ -49,11 +99,18 @@ func defaultVirtualRpkYaml() (RpkYaml, error) {
 	return y, nil
 }
 
+// Names of the default profile and cloud auth, which are used if no prior
+// profile or auth exists.
+const (
+	DefaultProfileName = "default"
+	DefaultAuthName    = "default"
+)
+
 // DefaultRpkProfile returns the default profile to use / create if no prior
 // profile exists.
 func DefaultRpkProfile() RpkProfile {
 	return RpkProfile{
-		Name:        "default",
+		Name:        DefaultProfileName,
 		Description: "Default rpk profile",
 	}
 }
@@ -62,7 +119,7 @@ func DefaultRpkProfile() RpkProfile {
 // auth exists.
 func DefaultRpkCloudAuth() RpkCloudAuth {
 	return RpkCloudAuth{
-		Name:         "default",
+		Name:         DefaultAuthName,
 		Organization: "Default organization",
 		OrgID:        "default-org-no-id",
 	}
@@ -70,7 +127,7 @@ func DefaultRpkCloudAuth() RpkCloudAuth {
 
 func emptyVirtualRpkYaml() RpkYaml {
 	return RpkYaml{
-		Version: 5,
+		Version: currentRpkYAMLVersion,
 	}
 }
 
@@ -80,6 +137,9 @@ type (
 	RpkYaml struct {
 		fileLocation string
 		fileRaw      []byte
+		// encryptKey, if set, is the passphrase that secrets are
+		// encrypted with when the file is written; see IsEncrypted.
+		encryptKey []byte
 
 		// Version is used for forwards and backwards compatibility.
 		// If Version is <= 1, the file is not a valid rpk.yaml file.
@@ -96,6 +156,33 @@ type (
 		CurrentCloudAuthKind  string         `json:"current_cloud_auth_kind" yaml:"current_cloud_auth_kind"`
 		Profiles              []RpkProfile   `json:"profiles" yaml:"profiles"`
 		CloudAuths            []RpkCloudAuth `json:"cloud_auth" yaml:"cloud_auth"`
+
+		// Environments groups profiles by environment name, such as
+		// "staging" or "prod". The first existing profile listed for
+		// an environment is its primary profile. If CurrentEnvironment
+		// is set, only the profiles in that environment are active,
+		// and the primary profile is used if the current profile is
+		// not one of them; see SetCurrentEnvironment.
+		Environments       map[string][]string `json:"environments,omitempty" yaml:"environments,omitempty"`
+		CurrentEnvironment string              `json:"current_environment,omitempty" yaml:"current_environment,omitempty"`
+
+		// Includes are paths to other rpk.yaml files whose profiles
+		// and cloud auths are merged into the loaded configuration.
+		// Relative paths are resolved against the directory of the
+		// including file first and, if no file exists there, against
+		// each directory in RPK_CONFIG_DIR (a list separated like
+		// PATH) in order. On name collisions, the including file
+		// wins.
+		Includes []string `json:"includes,omitempty" yaml:"includes,omitempty"`
+
+		// CloudDefaults are defaults for every cloud profile's cloud
+		// cluster; fields set in a profile take precedence.
+		CloudDefaults RpkCloudDefaults `json:"cloud_defaults,omitempty" yaml:"cloud_defaults,omitempty"`
+
+		// Extra contains any fields we do not know about, so that we
+		// preserve fields written by a newer rpk when we rewrite the
+		// file.
+		Extra map[string]any `json:"-" yaml:",inline,omitempty"`
 	}
 
 	RpkGlobals struct {
@@ -131,23 +218,64 @@ type (
 
 		// KafkaProtocolReqClientID is the client ID to use for the Kafka API.
 		KafkaProtocolReqClientID string `json:"kafka_protocol_request_client_id" yaml:"kafka_protocol_request_client_id"`
+
+		// Journal enables appending a line summarizing every write of
+		// the rpk.yaml to a sidecar "<rpk.yaml path>.journal" file.
+		Journal bool `json:"journal" yaml:"journal"`
 	}
 
 	RpkProfile struct {
 		Name         string               `json:"name" yaml:"name"`
 		Description  string               `json:"description" yaml:"description"`
+		Parent       string               `json:"parent,omitempty" yaml:"parent,omitempty"`
+		Aliases      []string             `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+		Labels       map[string]string    `json:"labels,omitempty" yaml:"labels,omitempty"`
+		Annotations  map[string]string    `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 		Prompt       string               `json:"prompt" yaml:"prompt"`
 		FromCloud    bool                 `json:"from_cloud" yaml:"from_cloud"`
+		ReadOnly     bool                 `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+		Insecure     bool                 `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+		CreatedAt    Timestamp            `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+		LastUsedAt   Timestamp            `json:"last_used_at,omitempty" yaml:"last_used_at,omitempty"`
 		CloudCluster RpkCloudCluster      `json:"cloud_cluster,omitempty" yaml:"cloud_cluster,omitempty"`
 		KafkaAPI     RpkKafkaAPI          `json:"kafka_api" yaml:"kafka_api"`
 		AdminAPI     RpkAdminAPI          `json:"admin_api" yaml:"admin_api"`
 		SR           RpkSchemaRegistryAPI `json:"schema_registry" yaml:"schema_registry"`
+		Extra        map[string]any       `json:"-" yaml:",inline,omitempty"`
+
+		// ProfileDefaults are defaults that commands fall back to when
+		// the corresponding flags are not specified.
+		ProfileDefaults RpkProfileDefaults `json:"defaults,omitempty" yaml:"defaults,omitempty"`
 
 		// We stash the config struct itself so that we can provide
 		// the logger / dev overrides.
 		c *Config
 	}
 
+	// RpkProfileDefaults contains per-profile command defaults. The zero
+	// value means no defaults.
+	RpkProfileDefaults struct {
+		// TopicPrefix, if non-empty, is prepended to topic names in
+		// 'rpk topic create' if the name does not already have the
+		// prefix.
+		TopicPrefix string `json:"topic_prefix,omitempty" yaml:"topic_prefix,omitempty"`
+
+		// Partitions, if positive, is the number of partitions to use
+		// in 'rpk topic create' if --partitions is not specified.
+		Partitions int `json:"partitions,omitempty" yaml:"partitions,omitempty"`
+
+		// Group, if non-empty, is the consumer group that 'rpk topic
+		// consume' uses if neither --group nor --partitions is
+		// specified; see DefaultConsumerGroup.
+		Group string `json:"group,omitempty" yaml:"group,omitempty"`
+	}
+
+	RpkCloudDefaults struct {
+		// ResourceGroup is the resource group (formerly namespace) of
+		// any cloud cluster that does not specify one.
+		ResourceGroup string `json:"resource_group,omitempty" yaml:"resource_group,omitempty"`
+	}
+
 	RpkCloudCluster struct {
 		Namespace     string `json:"namespace" yaml:"namespace"`
 		ResourceGroup string `json:"resource_group" yaml:"resource_group"`
@@ -162,21 +290,39 @@ type (
 	// RpkCloudAuth is unique by name and org ID. We support multiple auths
 	// per org ID in case a person wants to use client credentials and SSO.
 	RpkCloudAuth struct {
-		Name         string `json:"name" yaml:"name"`
-		Organization string `json:"organization" yaml:"organization"`
-		OrgID        string `json:"org_id" yaml:"org_id"`
-		Kind         string `json:"kind" yaml:"kind"`
-		AuthToken    string `json:"auth_token,omitempty" yaml:"auth_token,omitempty"`
-		RefreshToken string `json:"refresh_token,omitempty" yaml:"refresh_token,omitempty"`
-		ClientID     string `json:"client_id,omitempty" yaml:"client_id,omitempty"`
-		ClientSecret string `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+		Name         string         `json:"name" yaml:"name"`
+		Organization string         `json:"organization" yaml:"organization"`
+		OrgID        string         `json:"org_id" yaml:"org_id"`
+		Kind         string         `json:"kind" yaml:"kind"`
+		AuthToken    string         `json:"auth_token,omitempty" yaml:"auth_token,omitempty"`
+		RefreshToken string         `json:"refresh_token,omitempty" yaml:"refresh_token,omitempty"`
+		ClientID     string         `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+		ClientSecret string         `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+		CloudURL     string         `json:"cloud_url,omitempty" yaml:"cloud_url,omitempty"`
+		Extra        map[string]any `json:"-" yaml:",inline,omitempty"`
 	}
 
 	Duration struct{ time.Duration }
+
+	// Timestamp is a time that is encoded as RFC3339. The zero
+	// Timestamp is omitted when encoding.
+	Timestamp struct{ time.Time }
+
+	// MergeResult contains the names of profiles and auths that were
+	// added, skipped, or overwritten in RpkYaml.Merge.
+	MergeResult struct {
+		AddedProfiles       []string
+		SkippedProfiles     []string
+		OverwrittenProfiles []string
+		AddedAuths          []string
+		SkippedAuths        []string
+		OverwrittenAuths    []string
+	}
 )
 
-// Profile returns the given profile, or nil if it does not exist.
-// This is safe to call even if y is nil.
+// Profile returns the given profile, or nil if it does not exist. If no
+// profile has the given name, this returns the profile that has the name as
+// an alias. This is safe to call even if y is nil.
 func (y *RpkYaml) Profile(name string) *RpkProfile {
 	if y == nil {
 		return nil
@@ -186,12 +332,198 @@ func (y *RpkYaml) Profile(name string) *RpkProfile {
 			return &y.Profiles[i]
 		}
 	}
+	if name == "" {
+		return nil
+	}
+	for i, p := range y.Profiles {
+		for _, a := range p.Aliases {
+			if a == name {
+				return &y.Profiles[i]
+			}
+		}
+	}
 	return nil
 }
 
+// ProfilesWithLabel returns pointers to all profiles that have the given label.
+// If value is empty, this returns all profiles that have the label key,
+// regardless of the value.
+func (y *RpkYaml) ProfilesWithLabel(key, value string) []*RpkProfile {
+	var matches []*RpkProfile
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if v, ok := p.Labels[key]; ok && (value == "" || v == value) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// FindProfiles returns pointers to all profiles whose name contains substr,
+// compared case-insensitively, in config order. This is meant for completion
+// and "did you mean" suggestions; a single match can be treated as a unique
+// resolution.
+func (y *RpkYaml) FindProfiles(substr string) []*RpkProfile {
+	substr = strings.ToLower(substr)
+	var matches []*RpkProfile
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if strings.Contains(strings.ToLower(p.Name), substr) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// SelectProfiles returns pointers to all profiles whose name matches the glob
+// pattern, in config order. The pattern syntax is that of path.Match, e.g.
+// "prod-*", and names are matched case-sensitively. This returns an error
+// wrapping path.ErrBadPattern if the pattern is malformed, even if there are
+// no profiles to match.
+func (y *RpkYaml) SelectProfiles(pattern string) ([]*RpkProfile, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid profile pattern %q: %w", pattern, err)
+	}
+	var matches []*RpkProfile
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if ok, _ := path.Match(pattern, p.Name); ok {
+			matches = append(matches, p)
+		}
+	}
+	return matches, nil
+}
+
+// ProfileForBroker returns the first profile that has addr as one of its
+// Kafka API brokers. Addresses are compared without any scheme and with the
+// default Kafka port if no port is specified, so "localhost" matches a profile
+// with the broker "localhost:9092".
+func (y *RpkYaml) ProfileForBroker(addr string) (*RpkProfile, bool) {
+	want, ok := normalizeBrokerAddr(addr)
+	if !ok {
+		return nil, false
+	}
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		for _, b := range p.KafkaAPI.Brokers {
+			if have, ok := normalizeBrokerAddr(b); ok && have == want {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// normalizeBrokerAddr returns addr as a lowercase host:port, defaulting the
+// port to the default Kafka port.
+func normalizeBrokerAddr(addr string) (string, bool) {
+	_, host, port, err := rpknet.SplitSchemeHostPort(strings.TrimSpace(addr))
+	if err != nil || host == "" {
+		return "", false
+	}
+	if port == "" {
+		port = strconv.Itoa(DefaultKafkaPort)
+	}
+	return net.JoinHostPort(strings.ToLower(host), port), true
+}
+
+// ProfilesForCluster returns the cloud profiles that point at the cloud
+// cluster with the given name in the given resource group. Profiles that
+// have not been migrated from the older cloud_cluster.namespace field are
+// matched on their namespace.
+func (y *RpkYaml) ProfilesForCluster(resourceGroup, cluster string) []*RpkProfile {
+	var matches []*RpkProfile
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if !p.FromCloud {
+			continue
+		}
+		cc := &p.CloudCluster
+		rg := cc.ResourceGroup
+		if rg == "" {
+			rg = cc.Namespace
+		}
+		if rg == resourceGroup && cc.ClusterName == cluster {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// ProfileNames returns the names of all profiles, sorted case-insensitively.
+func (y *RpkYaml) ProfileNames() []string {
+	names := make([]string, 0, len(y.Profiles))
+	for _, p := range y.Profiles {
+		names = append(names, p.Name)
+	}
+	sortNames(names)
+	return names
+}
+
+// SortedProfiles returns pointers to all profiles, sorted by name as in
+// ProfileNames. Profiles with the same name, which Validate rejects but a
+// hand edited file can contain, are each returned in their file order.
+func (y *RpkYaml) SortedProfiles() []*RpkProfile {
+	ps := make([]*RpkProfile, 0, len(y.Profiles))
+	for i := range y.Profiles {
+		ps = append(ps, &y.Profiles[i])
+	}
+	sort.SliceStable(ps, func(i, j int) bool { return lessName(ps[i].Name, ps[j].Name) })
+	return ps
+}
+
+// AuthNames returns the names of all cloud auths, sorted case-insensitively.
+func (y *RpkYaml) AuthNames() []string {
+	names := make([]string, 0, len(y.CloudAuths))
+	for _, a := range y.CloudAuths {
+		names = append(names, a.Name)
+	}
+	sortNames(names)
+	return names
+}
+
+// sortNames sorts names case-insensitively, breaking ties between names that
+// differ only in case with a case-sensitive comparison.
+func sortNames(names []string) {
+	sort.Slice(names, func(i, j int) bool { return lessName(names[i], names[j]) })
+}
+
+// lessName is the ordering of sortNames.
+func lessName(l, r string) bool {
+	ll, lr := strings.ToLower(l), strings.ToLower(r)
+	if ll != lr {
+		return ll < lr
+	}
+	return l < r
+}
+
+// HasProfile returns whether a profile with the given name exists. An empty
+// name never matches.
+func (y *RpkYaml) HasProfile(name string) bool {
+	return name != "" && y.Profile(name) != nil
+}
+
+// SetCurrentProfile sets the current profile to the given profile, updates the
+// profile's last used time, and returns it, or returns ErrProfileNotFound if
+// the profile does not exist. If name is an alias, the current profile is set
+// to the name of the aliased profile.
+func (y *RpkYaml) SetCurrentProfile(name string) (*RpkProfile, error) {
+	if !y.HasProfile(name) {
+		return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, name)
+	}
+	p := y.Profile(name)
+	y.CurrentProfile = p.Name
+	p.Touch()
+	return p, nil
+}
+
 // PushProfile pushes a profile to the front, updates the current profile, and
-// returns the prior profile's auth and the current profile's auth.
+// returns the prior profile's auth and the current profile's auth. If the
+// profile has no creation time, it is stamped with the current time.
 func (y *RpkYaml) PushProfile(p RpkProfile) (priorAuth, currentAuth *RpkCloudAuth) {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = timestampNow()
+	}
 	priorAuth = y.CurrentAuth()
 	y.Profiles = append([]RpkProfile{p}, y.Profiles...)
 	if p.FromCloud {
@@ -203,6 +535,203 @@ func (y *RpkYaml) PushProfile(p RpkProfile) (priorAuth, currentAuth *RpkCloudAut
 	return priorAuth, currentAuth
 }
 
+// UpsertProfile replaces the profile with the same name in place, keeping its
+// position and creation time if p has none, or otherwise pushes p to the front
+// as a new profile. Unlike PushProfile, this never adds a second profile with
+// the same name, and it does not change the current profile. This returns
+// ChangeModified if a profile was replaced and ChangeAdded if it was added.
+// Profiles are matched by name only, never by alias: if p's name is an alias
+// of another profile, this returns an error wrapping ErrDuplicateProfile.
+func (y *RpkYaml) UpsertProfile(p RpkProfile) (string, error) {
+	if existing := y.profileNamed(p.Name); existing != nil {
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = existing.CreatedAt
+		}
+		*existing = p
+		return ChangeModified, nil
+	}
+	if err := y.checkNotAlias(p.Name); err != nil {
+		return "", err
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = timestampNow()
+	}
+	y.Profiles = append([]RpkProfile{p}, y.Profiles...)
+	return ChangeAdded, nil
+}
+
+// GetOrCreateProfile returns the profile with the given name, creating it at
+// the end of the profile list if it does not exist. Unlike PushProfile, this
+// does not change the current profile. The returned pointer points into
+// y.Profiles and is invalidated if profiles are later added or removed. As
+// with UpsertProfile, aliases are not matched, and a name that is an alias of
+// another profile returns an error wrapping ErrDuplicateProfile.
+func (y *RpkYaml) GetOrCreateProfile(name string) (*RpkProfile, error) {
+	if p := y.profileNamed(name); p != nil {
+		return p, nil
+	}
+	if err := y.checkNotAlias(name); err != nil {
+		return nil, err
+	}
+	y.Profiles = append(y.Profiles, RpkProfile{Name: name, CreatedAt: timestampNow()})
+	return &y.Profiles[len(y.Profiles)-1], nil
+}
+
+// profileNamed returns the profile with exactly the given name, ignoring
+// aliases, or nil if it does not exist.
+func (y *RpkYaml) profileNamed(name string) *RpkProfile {
+	for i := range y.Profiles {
+		if y.Profiles[i].Name == name {
+			return &y.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// checkNotAlias returns an error if name is an alias of an existing profile,
+// in which case a new profile with the name would shadow the alias.
+func (y *RpkYaml) checkNotAlias(name string) error {
+	if name == "" {
+		return nil
+	}
+	if p := y.Profile(name); p != nil && p.Name != name {
+		return fmt.Errorf("%w: %q is an alias of profile %q", ErrDuplicateProfile, name, p.Name)
+	}
+	return nil
+}
+
+// RenameProfile renames the profile named from to the given name. If the
+// renamed profile is the current profile, the current profile is updated as
+// well, as are any environments that list the profile.
+func (y *RpkYaml) RenameProfile(from, to string) error {
+	p := y.Profile(from)
+	if p == nil {
+		return fmt.Errorf("%w: %q", ErrProfileNotFound, from)
+	}
+	if y.HasProfile(to) {
+		return fmt.Errorf("%w: %q", ErrDuplicateProfile, to)
+	}
+	old := p.Name // from may be an alias
+	p.Name = to
+	if y.CurrentProfile == old || y.CurrentProfile == from {
+		y.CurrentProfile = to
+	}
+	for _, names := range y.Environments {
+		for i, name := range names {
+			if name == old {
+				names[i] = to
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteProfile removes the named profile, including from any environments
+// that list it. If the deleted profile was the current profile, the most
+// recently used remaining profile becomes the current profile; see
+// MostRecentlyUsedProfile. The current profile is cleared if no profiles
+// remain.
+func (y *RpkYaml) DeleteProfile(name string) error {
+	if p := y.Profile(name); p != nil {
+		name = p.Name // delete by alias
+	}
+	idx := -1
+	for i, p := range y.Profiles {
+		if p.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("%w: %q", ErrProfileNotFound, name)
+	}
+	y.Profiles = append(y.Profiles[:idx], y.Profiles[idx+1:]...)
+	for env, names := range y.Environments {
+		kept := names[:0]
+		for _, n := range names {
+			if n != name {
+				kept = append(kept, n)
+			}
+		}
+		y.Environments[env] = kept
+	}
+	if y.CurrentProfile == name {
+		y.CurrentProfile = ""
+		if p := y.MostRecentlyUsedProfile(); p != nil {
+			y.CurrentProfile = p.Name
+		}
+	}
+	return nil
+}
+
+// DedupeProfiles repairs an rpk.yaml that has multiple profiles with the same
+// name, which makes lookups ambiguous. For each name, only the first profile
+// with that name is kept if keepFirst is true, otherwise only the last is
+// kept. The surviving profiles keep their relative order. This returns the
+// name of each removed profile, in the order the profiles were listed.
+func (y *RpkYaml) DedupeProfiles(keepFirst bool) []string {
+	keep := make(map[string]int, len(y.Profiles)) // name => index to keep
+	for i, p := range y.Profiles {
+		if _, ok := keep[p.Name]; !ok || !keepFirst {
+			keep[p.Name] = i
+		}
+	}
+	if len(keep) == len(y.Profiles) {
+		return nil
+	}
+	var removed []string
+	kept := y.Profiles[:0]
+	for i, p := range y.Profiles {
+		if keep[p.Name] != i {
+			removed = append(removed, p.Name)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	y.Profiles = kept
+	return removed
+}
+
+// MostRecentlyUsedProfile returns the profile with the latest LastUsedAt, or
+// nil if there are no profiles. Ties, including profiles that have never been
+// used, are broken by name in ProfileNames order, so the result does not
+// depend on the order of profiles in the file.
+func (y *RpkYaml) MostRecentlyUsedProfile() *RpkProfile {
+	var mru *RpkProfile
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if mru == nil {
+			mru = p
+			continue
+		}
+		switch {
+		case p.LastUsedAt.After(mru.LastUsedAt.Time):
+			mru = p
+		case p.LastUsedAt.Equal(mru.LastUsedAt.Time) && lessName(p.Name, mru.Name):
+			mru = p
+		}
+	}
+	return mru
+}
+
+// CopyProfile copies the profile named src to a new profile named dst and
+// pushes the copy with PushProfile, returning the new profile.
+func (y *RpkYaml) CopyProfile(src, dst string) (*RpkProfile, error) {
+	p := y.Profile(src)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, src)
+	}
+	if y.HasProfile(dst) {
+		return nil, fmt.Errorf("%w: %q", ErrDuplicateProfile, dst)
+	}
+	dup := p.deepCopy()
+	dup.Name = dst
+	dup.CreatedAt = Timestamp{}
+	dup.LastUsedAt = Timestamp{}
+	y.PushProfile(dup)
+	return &y.Profiles[0], nil
+}
+
 // MoveProfileToFront moves the given profile to the front of the list.
 func (y *RpkYaml) MoveProfileToFront(p **RpkProfile) (priorAuth, currentAuth *RpkCloudAuth) {
 	priorAuth = y.CurrentAuth()
@@ -236,6 +765,20 @@ func (y *RpkYaml) LookupAuth(org, kind string) *RpkCloudAuth {
 	return nil
 }
 
+// HasAuth returns whether a cloud auth with the given name exists. An empty
+// name never matches.
+func (y *RpkYaml) HasAuth(name string) bool {
+	if y == nil || name == "" {
+		return false
+	}
+	for _, a := range y.CloudAuths {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // PushNewAuth pushes an auth to the front and sets it as the current auth.
 func (y *RpkYaml) PushNewAuth(a RpkCloudAuth) {
 	y.CloudAuths = append([]RpkCloudAuth{a}, y.CloudAuths...)
@@ -283,12 +826,172 @@ func (y *RpkYaml) DropAuth(a *RpkCloudAuth) {
 	}
 }
 
+// RenameAuth renames the cloud auth named from to the given name. Profiles
+// refer to auths by org ID and kind, so no profile needs updating.
+func (y *RpkYaml) RenameAuth(from, to string) error {
+	idx := -1
+	for i, a := range y.CloudAuths {
+		if a.Name == from {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("%w: %q", ErrAuthNotFound, from)
+	}
+	if from != to && y.HasAuth(to) {
+		return fmt.Errorf("%w: %q", ErrDuplicateAuth, to)
+	}
+	y.CloudAuths[idx].Name = to
+	return nil
+}
+
+// DeleteAuth removes the cloud auth with the given name. If any profiles
+// reference the auth, this returns ErrAuthInUse listing the profiles unless
+// force is true, in which case the profiles are detached from the cloud: their
+// FromCloud field and their cloud cluster auth are cleared. If the deleted
+// auth was the current auth, the current auth is cleared.
+func (y *RpkYaml) DeleteAuth(name string, force bool) error {
+	idx := -1
+	for i, a := range y.CloudAuths {
+		if a.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("%w: %q", ErrAuthNotFound, name)
+	}
+	a := y.CloudAuths[idx]
+
+	var attached []string
+	for _, p := range y.Profiles {
+		if p.CloudCluster.HasAuth(a) {
+			attached = append(attached, p.Name)
+		}
+	}
+	if len(attached) > 0 && !force {
+		sort.Strings(attached)
+		return fmt.Errorf("%w: %q is used by profiles %s", ErrAuthInUse, name, strings.Join(attached, ", "))
+	}
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if p.CloudCluster.HasAuth(a) {
+			p.FromCloud = false
+			p.CloudCluster.AuthOrgID = ""
+			p.CloudCluster.AuthKind = ""
+		}
+	}
+
+	y.CloudAuths = append(y.CloudAuths[:idx], y.CloudAuths[idx+1:]...)
+	if y.CurrentCloudAuthOrgID == a.OrgID && y.CurrentCloudAuthKind == a.Kind {
+		y.CurrentCloudAuthOrgID = ""
+		y.CurrentCloudAuthKind = ""
+	}
+	return nil
+}
+
+// UpdateAuthToken sets the auth token of the cloud auth with the given name,
+// as well as its refresh token if newRefresh is non-empty: a refresh grant
+// does not always return a new refresh token. The client ID and secret are
+// left untouched. This returns an error wrapping ErrAuthNotFound if the auth
+// does not exist.
+func (y *RpkYaml) UpdateAuthToken(authName, newToken, newRefresh string) error {
+	for i := range y.CloudAuths {
+		a := &y.CloudAuths[i]
+		if a.Name != authName {
+			continue
+		}
+		a.AuthToken = newToken
+		if newRefresh != "" {
+			a.RefreshToken = newRefresh
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrAuthNotFound, authName)
+}
+
+// PruneUnusedAuths removes every cloud auth that is neither the current auth
+// nor referenced by any profile's cloud cluster, and returns the names of the
+// removed auths in their original order.
+func (y *RpkYaml) PruneUnusedAuths() []string {
+	var pruned []string
+	kept := y.CloudAuths[:0]
+	for _, a := range y.CloudAuths {
+		used := a.OrgID == y.CurrentCloudAuthOrgID && a.Kind == y.CurrentCloudAuthKind
+		for i := 0; i < len(y.Profiles) && !used; i++ {
+			used = y.Profiles[i].CloudCluster.HasAuth(a)
+		}
+		if used {
+			kept = append(kept, a)
+			continue
+		}
+		pruned = append(pruned, a.Name)
+	}
+	y.CloudAuths = kept
+	return pruned
+}
+
+// RemoveAllCloud removes every cloud auth, the current cloud auth, the cloud
+// defaults, and every profile's cloud cluster, leaving only profiles for
+// self-hosted clusters. Profiles that were created from the cloud keep their
+// Kafka, admin, and schema registry settings but are no longer marked as
+// cloud profiles.
+func (y *RpkYaml) RemoveAllCloud() {
+	y.CloudAuths = nil
+	y.CurrentCloudAuthOrgID = ""
+	y.CurrentCloudAuthKind = ""
+	y.CloudDefaults = RpkCloudDefaults{}
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		p.FromCloud = false
+		p.CloudCluster = RpkCloudCluster{}
+	}
+}
+
 // CurrentAuth returns the auth corresponding to the current cloud auth, if
 // it exists.
 func (y *RpkYaml) CurrentAuth() *RpkCloudAuth {
 	return y.LookupAuth(y.CurrentCloudAuthOrgID, y.CurrentCloudAuthKind)
 }
 
+// ResolveCurrentProfile returns the current profile, or an error wrapping
+// ErrProfileNotFound if the current profile does not exist. If no profile is
+// selected, the only profile is used implicitly; if there is no profile this
+// returns ErrNoCurrentProfile, and if there are multiple profiles this returns
+// an error wrapping ErrNoCurrentProfile that names them.
+func (y *RpkYaml) ResolveCurrentProfile() (*RpkProfile, error) {
+	if y.CurrentProfile == "" {
+		switch len(y.Profiles) {
+		case 0:
+			return nil, ErrNoCurrentProfile
+		case 1:
+			return &y.Profiles[0], nil
+		default:
+			return nil, fmt.Errorf("%w: select one of %s with 'rpk profile use'", ErrNoCurrentProfile, strings.Join(y.ProfileNames(), ", "))
+		}
+	}
+	p := y.Profile(y.CurrentProfile)
+	if p == nil {
+		return nil, fmt.Errorf("%w: current profile %q", ErrProfileNotFound, y.CurrentProfile)
+	}
+	return p, nil
+}
+
+// ResolveCurrentAuth returns the current cloud auth, or ErrNoCurrentAuth if no
+// cloud auth is selected, or an error wrapping ErrAuthNotFound if the current
+// cloud auth does not exist.
+func (y *RpkYaml) ResolveCurrentAuth() (*RpkCloudAuth, error) {
+	if y.CurrentCloudAuthOrgID == "" && y.CurrentCloudAuthKind == "" {
+		return nil, ErrNoCurrentAuth
+	}
+	a := y.CurrentAuth()
+	if a == nil {
+		return nil, fmt.Errorf("%w: current cloud auth with org ID %q and kind %q", ErrAuthNotFound, y.CurrentCloudAuthOrgID, y.CurrentCloudAuthKind)
+	}
+	return a, nil
+}
+
 const (
 	CloudAuthUninitialized     = ""
 	CloudAuthSSO               = "sso"
@@ -315,6 +1018,74 @@ func (p *RpkProfile) Defaults() *RpkGlobals {
 	return &p.c.rpkYaml.Globals
 }
 
+// DefaultConsumerGroup returns the profile's default consumer group, or an
+// empty string if none is configured.
+func (p *RpkProfile) DefaultConsumerGroup() string {
+	if p == nil {
+		return ""
+	}
+	return p.ProfileDefaults.Group
+}
+
+// Defaults for the Kafka client timeouts, used if neither the profile nor the
+// globals set them. These are shorter than the Kafka client defaults: rpk is a
+// CLI and should not hang.
+const (
+	DefaultKafkaDialTimeout            = 3 * time.Second
+	DefaultKafkaRequestTimeoutOverhead = 5 * time.Second
+)
+
+// EffectiveKafkaAPI returns a copy of the profile's Kafka API with every
+// unset setting resolved to what rpk connects with: timeouts fall back to the
+// globals and then to the defaults above, client tuning is defaulted, the SASL
+// mechanism defaults to SCRAM-SHA-256, and brokers default to localhost unless
+// globals.no_default_cluster is set. The profile is not modified.
+//
+// Environment and flag overrides and parent profiles are resolved when the
+// configuration is loaded, so this is meant to be called on the virtual
+// profile.
+func (p *RpkProfile) EffectiveKafkaAPI() RpkKafkaAPI {
+	var g RpkGlobals
+	if p.c != nil {
+		g = p.c.rpkYaml.Globals
+	}
+	k := p.deepCopy().KafkaAPI
+	if len(k.Brokers) == 0 && !g.NoDefaultCluster {
+		k.Brokers = []string{net.JoinHostPort("127.0.0.1", strconv.Itoa(DefaultKafkaPort))}
+	}
+	for _, d := range []struct {
+		dst            *Duration
+		global         Duration
+		defaultTimeout time.Duration
+	}{
+		{&k.DialTimeout, g.DialTimeout, DefaultKafkaDialTimeout},
+		{&k.RequestTimeoutOverhead, g.RequestTimeoutOverhead, DefaultKafkaRequestTimeoutOverhead},
+	} {
+		switch {
+		case d.dst.Duration != 0:
+		case d.global.Duration != 0:
+			*d.dst = d.global
+		default:
+			d.dst.Duration = d.defaultTimeout
+		}
+	}
+	tuning := k.ClientTuning.WithDefaults()
+	k.ClientTuning = &tuning
+	mechanism := "SCRAM-SHA-256"
+	if k.SASL != nil {
+		if k.SASL.Mechanism == "" {
+			k.SASL.Mechanism = mechanism
+		}
+		mechanism = k.SASL.Mechanism
+	}
+	for _, s := range k.BrokerSASL {
+		if s != nil && s.Mechanism == "" {
+			s.Mechanism = mechanism
+		}
+	}
+	return k
+}
+
 // CurrentAuth returns the current cloud Auth.
 func (p *RpkProfile) CurrentAuth() *RpkCloudAuth {
 	return p.c.rpkYaml.LookupAuth(p.c.rpkYaml.CurrentCloudAuthOrgID, p.c.rpkYaml.CurrentCloudAuthKind)
@@ -325,6 +1096,24 @@ func (p *RpkProfile) DevOverrides() DevOverrides {
 	return p.c.devOverrides
 }
 
+// IsReadOnly returns whether the profile is marked read only, in which case
+// rpk refuses to run destructive commands against it.
+func (p *RpkProfile) IsReadOnly() bool {
+	return p != nil && p.ReadOnly
+}
+
+// Touch stamps the profile as last used now, and is called on the current
+// profile whenever the rpk.yaml is loaded. Touching is persisted lazily:
+// Write and WriteWithBackup only write a touched profile if something else in
+// the rpk.yaml also changed, so that read only commands do not rewrite the
+// file every time they run. Use WriteAt to persist a touch on its own. This
+// is a no-op if p is nil.
+func (p *RpkProfile) Touch() {
+	if p != nil {
+		p.LastUsedAt = timestampNow()
+	}
+}
+
 // HasSASLCredentials returns if both Kafka SASL user and password are empty.
 func (p *RpkProfile) HasSASLCredentials() bool {
 	s := p.KafkaAPI.SASL
@@ -349,27 +1138,511 @@ func (p *RpkProfile) VirtualAuth() *RpkCloudAuth {
 	return p.c.rpkYaml.LookupAuth(p.CloudCluster.AuthOrgID, p.CloudCluster.AuthKind)
 }
 
+// ResolveAuth returns the cloud auth in y that this profile's cloud cluster
+// refers to. This returns an error if the profile is not for a cloud cluster,
+// or ErrAuthNotFound if the referenced auth does not exist.
+func (p *RpkProfile) ResolveAuth(y *RpkYaml) (*RpkCloudAuth, error) {
+	cc := &p.CloudCluster
+	if !p.FromCloud || cc.AuthOrgID == "" && cc.AuthKind == "" {
+		return nil, fmt.Errorf("profile %q is not for a cloud cluster", p.Name)
+	}
+	a := y.LookupAuth(cc.AuthOrgID, cc.AuthKind)
+	if a == nil {
+		return nil, fmt.Errorf("%w: profile %q refers to org ID %q and kind %q", ErrAuthNotFound, p.Name, cc.AuthOrgID, cc.AuthKind)
+	}
+	return a, nil
+}
+
+// CloudAPIURL returns the URL of the cloud API this auth targets, which is
+// the production URL unless the auth has a cloud_url. A nil auth targets the
+// production URL.
+func (a *RpkCloudAuth) CloudAPIURL() string {
+	if a == nil || a.CloudURL == "" {
+		return cloudapi.ProdURL
+	}
+	return a.CloudURL
+}
+
 // HasClientCredentials returns if both ClientID and ClientSecret are non-empty.
 func (a *RpkCloudAuth) HasClientCredentials() bool {
 	return a.ClientID != "" && a.ClientSecret != ""
 }
 
-// Equals returns if the two cloud auths are the same, which is true
-// if the name matches (the name embeds the org name, ID, and auth kind).
-func (a *RpkCloudAuth) Equals(other *RpkCloudAuth) bool {
-	if a == nil && other == nil {
-		return true
+// Expired returns whether the auth token's "exp" claim has passed. The
+// token's signature is not verified. If the token is not a JWT or has no
+// expiry, this returns ErrUnknownTokenExpiry and callers should refresh the
+// token.
+func (a *RpkCloudAuth) Expired() (bool, error) {
+	if a.AuthToken == "" {
+		return false, fmt.Errorf("%w: missing token", ErrUnknownTokenExpiry)
 	}
-	if a == nil || other == nil {
-		return false
+	parsed, err := jwt.Parse([]byte(a.AuthToken))
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to parse jwt token: %v", ErrUnknownTokenExpiry, err)
 	}
-	return a.Name == other.Name
+	exp := parsed.Expiration()
+	if exp.IsZero() { // a missing "exp" field shows up as a zero time
+		return false, fmt.Errorf("%w: token has no expiry", ErrUnknownTokenExpiry)
+	}
+	return !time.Now().Before(exp), nil
 }
 
-// Returns if the raw config is the same as the one in memory.
-func (y *RpkYaml) isTheSameAsRawFile() bool {
-	var init, final *RpkYaml
-	if err := yaml.Unmarshal(y.fileRaw, &init); err != nil {
+// Refresh exchanges the auth's client credentials for a new token with an
+// OAuth 2.0 client credentials grant against tokenURL, storing the returned
+// access token (and refresh token, if any). If client is nil, a default client
+// with a 15s timeout is used. A client secret reference (see ResolveSecret)
+// is resolved against the OS filesystem; use RefreshWithFs to resolve it
+// against another filesystem.
+func (a *RpkCloudAuth) Refresh(ctx context.Context, tokenURL string, client *http.Client) error {
+	return a.RefreshWithFs(ctx, afero.NewOsFs(), tokenURL, client)
+}
+
+// RefreshWithFs is Refresh, resolving a client secret reference against fs.
+func (a *RpkCloudAuth) RefreshWithFs(ctx context.Context, fs afero.Fs, tokenURL string, client *http.Client) error {
+	if !a.HasClientCredentials() {
+		return fmt.Errorf("cloud auth %q does not have client credentials", a.Name)
+	}
+	secret, err := ResolveSecret(fs, a.ClientSecret)
+	if err != nil {
+		return fmt.Errorf("unable to resolve client secret for cloud auth %q: %w", a.Name, err)
+	}
+	kvs := []string{
+		"grant_type", "client_credentials",
+		"client_id", a.ClientID,
+		"client_secret", secret,
+	}
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	cl := httpapi.NewClient(httpapi.HTTPClient(client))
+	if err := cl.PostForm(ctx, tokenURL, nil, httpapi.Values(kvs...), &token); err != nil {
+		return fmt.Errorf("unable to refresh token for cloud auth %q: %w", a.Name, err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("unable to refresh token for cloud auth %q: response is missing an access token", a.Name)
+	}
+	a.AuthToken = token.AccessToken
+	if token.RefreshToken != "" {
+		a.RefreshToken = token.RefreshToken
+	}
+	return nil
+}
+
+// Equals returns if the two cloud auths are the same, which is true
+// if the name matches (the name embeds the org name, ID, and auth kind).
+func (a *RpkCloudAuth) Equals(other *RpkCloudAuth) bool {
+	if a == nil && other == nil {
+		return true
+	}
+	if a == nil || other == nil {
+		return false
+	}
+	return a.Name == other.Name
+}
+
+// SameCredentials returns whether two cloud auths, which may be named
+// differently, authenticate as the same identity against the same cloud API.
+// Auths with client credentials are the same if their client ID and secret
+// match. Otherwise, auths are the same if their tokens have the same subject;
+// tokens that are not JWTs must match exactly. Names, organizations, and
+// every other field are ignored. Auths without credentials are never the
+// same as another auth.
+func (a *RpkCloudAuth) SameCredentials(b *RpkCloudAuth) bool {
+	if a == nil || b == nil || a.CloudAPIURL() != b.CloudAPIURL() {
+		return false
+	}
+	if a.HasClientCredentials() || b.HasClientCredentials() {
+		return a.ClientID == b.ClientID && a.ClientSecret == b.ClientSecret
+	}
+	if a.AuthToken == "" || b.AuthToken == "" {
+		return false
+	}
+	if a.AuthToken == b.AuthToken {
+		return true
+	}
+	subject := func(token string) string {
+		parsed, err := jwt.Parse([]byte(token))
+		if err != nil {
+			return ""
+		}
+		return parsed.Subject()
+	}
+	sub := subject(a.AuthToken)
+	return sub != "" && sub == subject(b.AuthToken)
+}
+
+// Merge imports the profiles and cloud auths from other. If a profile with the
+// same name, or an auth with the same org ID and kind, already exists, it is
+// replaced if overwrite is true and skipped otherwise. Profiles are matched by
+// name only; a profile whose name is an alias of an existing profile is always
+// skipped. The current profile and current cloud auth only change if they do
+// not exist after merging, in which case other's current profile and auth are
+// used. Merged profiles and auths are copied and do not share memory with
+// other.
+func (y *RpkYaml) Merge(other RpkYaml, overwrite bool) MergeResult {
+	var r MergeResult
+	for _, p := range other.Profiles {
+		dup := p.deepCopy()
+		existing := y.profileNamed(p.Name)
+		switch {
+		case existing == nil && y.checkNotAlias(p.Name) != nil:
+			r.SkippedProfiles = append(r.SkippedProfiles, p.Name)
+		case existing == nil:
+			y.Profiles = append(y.Profiles, dup)
+			r.AddedProfiles = append(r.AddedProfiles, p.Name)
+		case overwrite:
+			dup.c = existing.c
+			*existing = dup
+			r.OverwrittenProfiles = append(r.OverwrittenProfiles, p.Name)
+		default:
+			r.SkippedProfiles = append(r.SkippedProfiles, p.Name)
+		}
+	}
+	for _, a := range other.CloudAuths {
+		a.Extra = maps.Clone(a.Extra)
+		existing := y.LookupAuth(a.OrgID, a.Kind)
+		switch {
+		case existing == nil:
+			y.CloudAuths = append(y.CloudAuths, a)
+			r.AddedAuths = append(r.AddedAuths, a.Name)
+		case overwrite:
+			*existing = a
+			r.OverwrittenAuths = append(r.OverwrittenAuths, a.Name)
+		default:
+			r.SkippedAuths = append(r.SkippedAuths, a.Name)
+		}
+	}
+	if y.profileNamed(y.CurrentProfile) == nil && y.profileNamed(other.CurrentProfile) != nil {
+		y.CurrentProfile = other.CurrentProfile
+	}
+	if y.CurrentAuth() == nil && y.LookupAuth(other.CurrentCloudAuthOrgID, other.CurrentCloudAuthKind) != nil {
+		y.CurrentCloudAuthOrgID = other.CurrentCloudAuthOrgID
+		y.CurrentCloudAuthKind = other.CurrentCloudAuthKind
+	}
+	return r
+}
+
+// MergeFile reads the rpk.yaml at path and merges its profiles and cloud
+// auths into y, similar to merging kubeconfig files: existing profiles and
+// auths are kept on collision, and the collisions are reported as skipped in
+// the returned result. Relative TLS paths in the file are resolved against the
+// directory of the file. If setCurrent is true, the file's current profile and
+// cloud auth are selected, even if they collided with an existing profile or
+// auth; otherwise they are only selected if y has no current profile or auth.
+func (y *RpkYaml) MergeFile(fs afero.Fs, path string, setCurrent bool) (MergeResult, error) {
+	abs, file, err := readFile(fs, path)
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	var other RpkYaml
+	if err := yaml.Unmarshal(file, &other); err != nil {
+		return MergeResult{}, fmt.Errorf("unable to yaml decode %s: %w", abs, err)
+	}
+	if other.Version > currentRpkYAMLVersion {
+		return MergeResult{}, fmt.Errorf("%s is using a newer rpk.yaml format (version %d) than we understand (up to version %d), please upgrade rpk", abs, other.Version, currentRpkYAMLVersion)
+	}
+	other.resolvePaths(filepath.Dir(abs))
+
+	r := y.Merge(other, false)
+	if setCurrent {
+		if y.HasProfile(other.CurrentProfile) {
+			y.CurrentProfile = other.CurrentProfile
+		}
+		if y.LookupAuth(other.CurrentCloudAuthOrgID, other.CurrentCloudAuthKind) != nil {
+			y.CurrentCloudAuthOrgID = other.CurrentCloudAuthOrgID
+			y.CurrentCloudAuthKind = other.CurrentCloudAuthKind
+		}
+	}
+	return r, nil
+}
+
+// Redacted returns a copy of the rpk.yaml with all cloud auth tokens and
+// client secrets replaced with "(REDACTED)". This is meant for printing the
+// file; the receiver is not modified.
+func (y *RpkYaml) Redacted() RpkYaml {
+	const redacted = "(REDACTED)"
+	dup := y.Clone()
+	for i := range dup.CloudAuths {
+		a := &dup.CloudAuths[i]
+		for _, s := range []*string{&a.AuthToken, &a.RefreshToken, &a.ClientSecret} {
+			if *s != "" {
+				*s = redacted
+			}
+		}
+	}
+	return dup
+}
+
+// ExportProfile returns a minimal rpk.yaml that contains only the given
+// profile, selected as the current profile, so that the profile can be shared
+// on its own. Any settings the profile inherits from a parent are included
+// directly. If includeAuth is true and the profile is for a cloud cluster,
+// the cloud auth the profile refers to is included and selected as well. If
+// stripSecrets is true, the SASL password and cloud auth tokens and client
+// secret are removed.
+func (y *RpkYaml) ExportProfile(name string, includeAuth, stripSecrets bool) (RpkYaml, error) {
+	dup := y.Clone()
+	if err := dup.resolveParents(); err != nil {
+		return RpkYaml{}, err
+	}
+	p := dup.Profile(name)
+	if p == nil {
+		return RpkYaml{}, fmt.Errorf("%w: %q", ErrProfileNotFound, name)
+	}
+	p.Parent = ""
+	if stripSecrets {
+		for _, s := range append([]*SASL{p.KafkaAPI.SASL}, maps.Values(p.KafkaAPI.BrokerSASL)...) {
+			if s == nil {
+				continue
+			}
+			s.Password = ""
+			if s.GSSAPI != nil {
+				s.GSSAPI.Password = ""
+			}
+		}
+	}
+	export := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: p.Name,
+		Profiles:       []RpkProfile{*p},
+		CloudDefaults:  dup.CloudDefaults,
+	}
+	if includeAuth && p.FromCloud {
+		a, err := p.ResolveAuth(&dup)
+		if err != nil {
+			return RpkYaml{}, err
+		}
+		if stripSecrets {
+			a.AuthToken, a.RefreshToken, a.ClientSecret = "", "", ""
+		}
+		export.CloudAuths = []RpkCloudAuth{*a}
+		export.CurrentCloudAuthOrgID = a.OrgID
+		export.CurrentCloudAuthKind = a.Kind
+	}
+	return export, nil
+}
+
+// Clone returns a deep copy of the rpk.yaml that can be modified without
+// affecting the receiver. The clone keeps the file location and the raw file
+// contents of the receiver, meaning writing the clone writes to the same path
+// and is a no-op if the clone is unchanged from the loaded file.
+func (y *RpkYaml) Clone() RpkYaml {
+	dup := *y
+	dup.fileRaw = append([]byte(nil), y.fileRaw...)
+	dup.encryptKey = append([]byte(nil), y.encryptKey...)
+	dup.Profiles = nil
+	for _, p := range y.Profiles {
+		dup.Profiles = append(dup.Profiles, p.deepCopy())
+	}
+	dup.CloudAuths = nil
+	for _, a := range y.CloudAuths {
+		a.Extra = maps.Clone(a.Extra)
+		dup.CloudAuths = append(dup.CloudAuths, a)
+	}
+	dup.Includes = append([]string(nil), y.Includes...)
+	if y.Environments != nil {
+		dup.Environments = make(map[string][]string, len(y.Environments))
+		for env, names := range y.Environments {
+			dup.Environments[env] = append([]string(nil), names...)
+		}
+	}
+	dup.Extra = maps.Clone(y.Extra)
+	return dup
+}
+
+func (p *RpkProfile) deepCopy() RpkProfile {
+	dupTLS := func(t *TLS) *TLS {
+		if t == nil {
+			return nil
+		}
+		dup := *t
+		return &dup
+	}
+	dupSASL := func(s *SASL) *SASL {
+		if s == nil {
+			return nil
+		}
+		dup := *s
+		if dup.OAuth != nil {
+			oauth := *dup.OAuth
+			dup.OAuth = &oauth
+		}
+		if dup.GSSAPI != nil {
+			gssapi := *dup.GSSAPI
+			dup.GSSAPI = &gssapi
+		}
+		return &dup
+	}
+	dup := *p
+	dup.Labels = maps.Clone(p.Labels)
+	dup.Annotations = maps.Clone(p.Annotations)
+	dup.Aliases = append([]string(nil), p.Aliases...)
+	dup.Extra = maps.Clone(p.Extra)
+	dup.KafkaAPI.Brokers = append([]string(nil), p.KafkaAPI.Brokers...)
+	dup.KafkaAPI.TLS = dupTLS(p.KafkaAPI.TLS)
+	dup.KafkaAPI.SASL = dupSASL(p.KafkaAPI.SASL)
+	if p.KafkaAPI.BrokerSASL != nil {
+		dup.KafkaAPI.BrokerSASL = make(map[string]*SASL, len(p.KafkaAPI.BrokerSASL))
+		for addr, s := range p.KafkaAPI.BrokerSASL {
+			dup.KafkaAPI.BrokerSASL[addr] = dupSASL(s)
+		}
+	}
+	if p.KafkaAPI.ClientTuning != nil {
+		tuning := *p.KafkaAPI.ClientTuning
+		if tuning.KeepAlive != nil {
+			keepAlive := *tuning.KeepAlive
+			tuning.KeepAlive = &keepAlive
+		}
+		dup.KafkaAPI.ClientTuning = &tuning
+	}
+	dup.AdminAPI.Addresses = append([]string(nil), p.AdminAPI.Addresses...)
+	dup.AdminAPI.TLS = dupTLS(p.AdminAPI.TLS)
+	dup.SR.Addresses = append([]string(nil), p.SR.Addresses...)
+	dup.SR.TLS = dupTLS(p.SR.TLS)
+	return dup
+}
+
+// Validate checks that the rpk.yaml's cross references are valid: the current
+// profile and current cloud auth must exist if set, every profile's cloud
+// cluster auth must exist if set, and profile and auth names must be unique.
+// Profile aliases must be unique across all profiles and must not be the name
+// of a profile. If the current profile uses a cloud auth, that auth must be
+// the current cloud auth; SyncCurrentCloudAuth fixes this. All problems are
+// returned joined into one error.
+func (y *RpkYaml) Validate() error {
+	var errs []error
+	if y.CurrentProfile != "" && !y.HasProfile(y.CurrentProfile) {
+		errs = append(errs, fmt.Errorf("current profile %q does not exist", y.CurrentProfile))
+	}
+	if (y.CurrentCloudAuthOrgID != "" || y.CurrentCloudAuthKind != "") && y.CurrentAuth() == nil {
+		errs = append(errs, fmt.Errorf("current cloud auth with org ID %q and kind %q does not exist", y.CurrentCloudAuthOrgID, y.CurrentCloudAuthKind))
+	}
+	if p, a := y.currentCloudAuthMismatch(); a != nil {
+		errs = append(errs, fmt.Errorf("current profile %q uses cloud auth with org ID %q and kind %q, but the current cloud auth has org ID %q and kind %q", p.Name, a.OrgID, a.Kind, y.CurrentCloudAuthOrgID, y.CurrentCloudAuthKind))
+	}
+	profiles := make(map[string]struct{})
+	for _, p := range y.Profiles {
+		if _, ok := profiles[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("profile name %q is used more than once", p.Name))
+		}
+		profiles[p.Name] = struct{}{}
+	}
+	aliases := make(map[string]string) // alias => profile
+	for _, p := range y.Profiles {
+		for _, a := range p.Aliases {
+			if a == "" {
+				errs = append(errs, fmt.Errorf("profile %q has an empty alias", p.Name))
+				continue
+			}
+			if owner, ok := aliases[a]; ok {
+				errs = append(errs, fmt.Errorf("alias %q is used by both profile %q and profile %q", a, owner, p.Name))
+				continue
+			}
+			if _, ok := profiles[a]; ok {
+				errs = append(errs, fmt.Errorf("alias %q of profile %q is the name of a profile", a, p.Name))
+			}
+			aliases[a] = p.Name
+		}
+	}
+	for _, p := range y.Profiles {
+		cc := &p.CloudCluster
+		if (cc.AuthOrgID != "" || cc.AuthKind != "") && y.LookupAuth(cc.AuthOrgID, cc.AuthKind) == nil {
+			errs = append(errs, fmt.Errorf("profile %q cloud auth with org ID %q and kind %q does not exist", p.Name, cc.AuthOrgID, cc.AuthKind))
+		}
+	}
+	auths := make(map[string]struct{})
+	for _, a := range y.CloudAuths {
+		if _, ok := auths[a.Name]; ok {
+			errs = append(errs, fmt.Errorf("cloud auth name %q is used more than once", a.Name))
+		}
+		auths[a.Name] = struct{}{}
+	}
+	if y.CurrentEnvironment != "" {
+		if _, ok := y.Environments[y.CurrentEnvironment]; !ok {
+			errs = append(errs, fmt.Errorf("current environment %q does not exist", y.CurrentEnvironment))
+		}
+	}
+	for _, env := range y.EnvironmentNames() {
+		for _, name := range y.Environments[env] {
+			if !y.HasProfile(name) {
+				errs = append(errs, fmt.Errorf("environment %q profile %q does not exist", env, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncCurrentCloudAuth makes the cloud auth of the current profile the
+// current cloud auth, if the current profile uses a cloud auth that is not
+// current. This returns whether the current cloud auth was changed. Nothing
+// is changed if the current profile does not use a cloud auth, or if its auth
+// does not exist.
+func (y *RpkYaml) SyncCurrentCloudAuth() bool {
+	_, a := y.currentCloudAuthMismatch()
+	if a == nil {
+		return false
+	}
+	y.CurrentCloudAuthOrgID = a.OrgID
+	y.CurrentCloudAuthKind = a.Kind
+	return true
+}
+
+// currentCloudAuthMismatch returns the current profile and its cloud auth if
+// that auth exists and is not the current cloud auth. A current cloud auth
+// that does not exist is not a mismatch, since Validate reports it already.
+func (y *RpkYaml) currentCloudAuthMismatch() (*RpkProfile, *RpkCloudAuth) {
+	p := y.Profile(y.CurrentProfile)
+	if p == nil {
+		return nil, nil
+	}
+	cc := &p.CloudCluster
+	if cc.AuthOrgID == "" && cc.AuthKind == "" {
+		return nil, nil
+	}
+	a := y.LookupAuth(cc.AuthOrgID, cc.AuthKind)
+	if a == nil {
+		return nil, nil
+	}
+	cur := y.CurrentAuth()
+	if cur == a || cur == nil && (y.CurrentCloudAuthOrgID != "" || y.CurrentCloudAuthKind != "") {
+		return nil, nil
+	}
+	return p, a
+}
+
+// EqualIgnoringSecrets returns whether y and other are the same once SASL
+// passwords and cloud auth tokens and client secrets are disregarded. Unlike
+// isTheSameAsRawFile, this is meant to compare configs that are functionally
+// the same across environments that use different credentials.
+func (y *RpkYaml) EqualIgnoringSecrets(other RpkYaml) bool {
+	encode := func(y *RpkYaml) ([]byte, error) {
+		dup := y.Clone()
+		for _, s := range dup.secretFields() {
+			*s = ""
+		}
+		return yaml.Marshal(&dup)
+	}
+	l, err := encode(y)
+	if err != nil {
+		return false
+	}
+	r, err := encode(&other)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(l, r)
+}
+
+// Returns if the raw config is the same as the one in memory.
+func (y *RpkYaml) isTheSameAsRawFile() bool {
+	return y.isTheSameAs(y.fileRaw)
+}
+
+// Returns if the given raw config is the same as the one in memory.
+func (y *RpkYaml) isTheSameAs(raw []byte) bool {
+	var init, final *RpkYaml
+	if err := yaml.Unmarshal(raw, &init); err != nil {
 		return false
 	}
 	// Avoid DeepEqual comparisons on non-exported fields.
@@ -380,38 +1653,688 @@ func (y *RpkYaml) isTheSameAsRawFile() bool {
 	if err := yaml.Unmarshal(finalRaw, &final); err != nil {
 		return false
 	}
+	// Last used timestamps alone are not worth rewriting the file for;
+	// see RpkProfile.Touch.
+	for _, y := range []*RpkYaml{init, final} {
+		if y == nil {
+			continue
+		}
+		for i := range y.Profiles {
+			y.Profiles[i].LastUsedAt = Timestamp{}
+		}
+	}
 	return reflect.DeepEqual(init, final)
 }
 
+// Returns if no file was loaded and the config in memory is the default
+// virtual config, i.e., there is nothing the user asked to persist.
+func (y *RpkYaml) isTheSameAsDefault() bool {
+	if len(y.fileRaw) > 0 {
+		return false
+	}
+	def, err := defaultVirtualRpkYaml()
+	if err != nil {
+		return false
+	}
+	defRaw, err := yaml.Marshal(def)
+	if err != nil {
+		return false
+	}
+	finalRaw, err := yaml.Marshal(y)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(defRaw, finalRaw)
+}
+
+// migrateUnversioned migrates an rpk.yaml that predates the version field.
+// Such files can contain an unnamed profile and no current profile. We name
+// the first unnamed profile DefaultProfileName if no profile already has that
+// name, and if there is no current profile, we select the first profile.
+// Existing names and settings are never modified, so this is idempotent.
+func (y *RpkYaml) migrateUnversioned() {
+	if !y.HasProfile(DefaultProfileName) {
+		if p := y.Profile(""); p != nil {
+			p.Name = DefaultProfileName
+		}
+	}
+	if y.CurrentProfile == "" && len(y.Profiles) > 0 {
+		y.CurrentProfile = y.Profiles[0].Name
+	}
+}
+
+// trimNames trims surrounding whitespace from profile and cloud auth names
+// and the current profile, which copy-paste often leaves behind and which
+// would otherwise never match a lookup. This returns an error if a trimmed
+// name collides with another profile or auth.
+func (y *RpkYaml) trimNames() error {
+	y.CurrentProfile = strings.TrimSpace(y.CurrentProfile)
+	trim := func(what string, names []*string) error {
+		seen := make(map[string]string, len(names)) // trimmed => original
+		for _, name := range names {
+			trimmed := strings.TrimSpace(*name)
+			if orig, ok := seen[trimmed]; ok && (orig != trimmed || *name != trimmed) {
+				return fmt.Errorf("%s names %q and %q are the same once surrounding whitespace is trimmed", what, orig, *name)
+			}
+			seen[trimmed] = *name
+			*name = trimmed
+		}
+		return nil
+	}
+	profiles := make([]*string, 0, len(y.Profiles))
+	for i := range y.Profiles {
+		profiles = append(profiles, &y.Profiles[i].Name)
+	}
+	if err := trim("profile", profiles); err != nil {
+		return fmt.Errorf("%w: %v", ErrDuplicateProfile, err)
+	}
+	auths := make([]*string, 0, len(y.CloudAuths))
+	for i := range y.CloudAuths {
+		auths = append(auths, &y.CloudAuths[i].Name)
+	}
+	if err := trim("cloud auth", auths); err != nil {
+		return fmt.Errorf("%w: %v", ErrDuplicateAuth, err)
+	}
+	return nil
+}
+
+// useImplicitProfile selects the only profile as the current profile, and
+// is called if the loaded file selects no profile. This is only done in the
+// virtual rpk.yaml, so that single-profile setups work without ever running
+// 'rpk profile use'.
+func (y *RpkYaml) useImplicitProfile() {
+	if len(y.Profiles) == 1 {
+		y.CurrentProfile = y.Profiles[0].Name
+	}
+}
+
+// applyCloudDefaults fills in unset cloud cluster fields of every cloud
+// profile from the cloud defaults. This is only applied to the virtual
+// rpk.yaml, so that the defaults are never written into each profile and
+// changing a default continues to apply to every profile.
+func (y *RpkYaml) applyCloudDefaults() {
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		if p.FromCloud && p.CloudCluster.ResourceGroup == "" {
+			p.CloudCluster.ResourceGroup = y.CloudDefaults.ResourceGroup
+		}
+	}
+}
+
+// resolveParents materializes profile inheritance: every profile with a
+// parent inherits the settings of its parent, which can itself have a parent.
+// Fields that are set in a profile take precedence over the inherited fields,
+// and nested sections such as kafka_api.tls are merged field by field. The
+// name, description, and timestamps of a parent are never inherited.
+func (y *RpkYaml) resolveParents() error {
+	resolved := make(map[string]bool)
+	var resolve func(p *RpkProfile, chain []string) error
+	resolve = func(p *RpkProfile, chain []string) error {
+		if p.Parent == "" || resolved[p.Name] {
+			return nil
+		}
+		chain = append(chain, p.Name)
+		for _, seen := range chain {
+			if seen == p.Parent {
+				return fmt.Errorf("profile parent cycle detected: %s -> %s", strings.Join(chain, " -> "), p.Parent)
+			}
+		}
+		parent := y.Profile(p.Parent)
+		if parent == nil {
+			return fmt.Errorf("%w: %q, the parent of profile %q", ErrProfileNotFound, p.Parent, p.Name)
+		}
+		if err := resolve(parent, chain); err != nil {
+			return err
+		}
+		inherited, err := inheritProfile(parent, p)
+		if err != nil {
+			return fmt.Errorf("unable to inherit profile %q from %q: %v", p.Name, p.Parent, err)
+		}
+		*p = inherited
+		resolved[p.Name] = true
+		return nil
+	}
+	for i := range y.Profiles {
+		if err := resolve(&y.Profiles[i], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritProfile returns child with every unset field filled in from parent.
+func inheritProfile(parent, child *RpkProfile) (RpkProfile, error) {
+	toMap := func(p *RpkProfile) (map[string]any, error) {
+		raw, err := yaml.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]any
+		return m, yaml.Unmarshal(raw, &m)
+	}
+	dst, err := toMap(parent)
+	if err != nil {
+		return RpkProfile{}, err
+	}
+	src, err := toMap(child)
+	if err != nil {
+		return RpkProfile{}, err
+	}
+	for _, k := range []string{"name", "description", "parent", "aliases", "annotations", "created_at", "last_used_at"} {
+		delete(dst, k)
+	}
+	mergeYamlMaps(dst, src)
+
+	raw, err := yaml.Marshal(dst)
+	if err != nil {
+		return RpkProfile{}, err
+	}
+	var p RpkProfile
+	return p, yaml.Unmarshal(raw, &p)
+}
+
+// mergeYamlMaps merges src into dst: nested maps are merged recursively, and
+// any other value in src that is not empty replaces the value in dst.
+func mergeYamlMaps(dst, src map[string]any) {
+	for k, v := range src {
+		sub, isMap := v.(map[string]any)
+		if isMap {
+			if dsub, ok := dst[k].(map[string]any); ok {
+				mergeYamlMaps(dsub, sub)
+				continue
+			}
+		}
+		if !isMap {
+			switch v := v.(type) {
+			case nil:
+				continue
+			case []any:
+				if len(v) == 0 {
+					continue
+				}
+			default:
+				if reflect.ValueOf(v).IsZero() {
+					continue
+				}
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// resolvePaths expands $VAR and ${VAR} environment variables and a leading ~
+// in every profile's TLS file paths and GSSAPI keytab path, and resolves
+// relative paths against dir, the directory containing the rpk.yaml.
+// Expansion always applies: a literal $ in a path must be avoided, since an
+// unset variable expands to the empty string.
+func (y *RpkYaml) resolvePaths(dir string) {
+	for i := range y.Profiles {
+		p := &y.Profiles[i]
+		for _, t := range []*TLS{p.KafkaAPI.TLS, p.AdminAPI.TLS, p.SR.TLS} {
+			if t == nil {
+				continue
+			}
+			for _, path := range []*string{&t.TruststoreFile, &t.CertFile, &t.KeyFile} {
+				*path = resolvePath(*path, dir)
+			}
+		}
+		for _, s := range append([]*SASL{p.KafkaAPI.SASL}, maps.Values(p.KafkaAPI.BrokerSASL)...) {
+			if s != nil && s.GSSAPI != nil {
+				s.GSSAPI.Keytab = resolvePath(s.GSSAPI.Keytab, dir)
+			}
+		}
+	}
+}
+
+func resolvePath(path, dir string) string {
+	if path == "" {
+		return ""
+	}
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	return path
+}
+
+// LoadRpkYamlOrDefault loads the rpk.yaml at the given path, returning whether
+// the file exists. If the file does not exist, this returns the default
+// rpk.yaml with its location set to path. This only returns an error if the
+// file exists and cannot be read or is not a valid rpk.yaml.
+func LoadRpkYamlOrDefault(fs afero.Fs, path string) (RpkYaml, bool, error) {
+	abs, file, err := readFile(fs, path)
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return RpkYaml{}, false, err
+		}
+		y, err := defaultVirtualRpkYaml()
+		if err != nil {
+			return RpkYaml{}, false, err
+		}
+		y.fileLocation = abs
+		return y, false, nil
+	}
+
+	y, err := parseRpkYaml(file, abs)
+	if err != nil {
+		return RpkYaml{}, true, err
+	}
+	y.fileLocation = abs
+	y.fileRaw = file
+	return y, true, nil
+}
+
+// Reload re-reads the rpk.yaml from its file location, replacing y in place
+// so that holders of y see external edits. The file location is preserved.
+// If the file cannot be read or parsed, y is left unchanged; a deleted file
+// returns an error wrapping os.ErrNotExist. Like LoadRpkYamlOrDefault, this
+// does not resolve includes, parents, or secret references.
+func (y *RpkYaml) Reload(fs afero.Fs) error {
+	if y.fileLocation == "" {
+		return errors.New("unable to reload rpk.yaml: it was not loaded from a file")
+	}
+	file, err := afero.ReadFile(fs, y.fileLocation)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to reload %s: the file no longer exists: %w", y.fileLocation, err)
+		}
+		return fmt.Errorf("unable to reload %s: %w", y.fileLocation, err)
+	}
+	reloaded, err := parseRpkYaml(file, y.fileLocation)
+	if err != nil {
+		return err
+	}
+	reloaded.fileLocation = y.fileLocation
+	reloaded.fileRaw = file
+	*y = reloaded
+	return nil
+}
+
+// LoadRpkYamlFromReader parses an rpk.yaml from r, for rpk.yaml files that do
+// not come from the filesystem, such as files fetched from a secrets manager.
+// The returned rpk.yaml has no file location: Write writes to the default
+// rpk.yaml path, and WriteAt can be used to write elsewhere. As with
+// LoadRpkYamlOrDefault, includes, parents, and secret references are not
+// resolved.
+func LoadRpkYamlFromReader(r io.Reader) (RpkYaml, error) {
+	file, err := io.ReadAll(r)
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to read rpk.yaml: %v", err)
+	}
+	return parseRpkYaml(file, "rpk.yaml")
+}
+
+// maxRpkYamlURLSize bounds how much LoadRpkYamlFromURL reads.
+const maxRpkYamlURLSize = 16 << 20
+
+// LoadRpkYamlFromURL fetches and parses an rpk.yaml served over HTTP, using
+// http.DefaultClient if cl is nil. Like LoadRpkYamlFromReader, the returned
+// rpk.yaml has no file location. Any non-2xx response is an error. Query
+// parameters are left out of errors, since they often hold signatures.
+func LoadRpkYamlFromURL(ctx context.Context, cl *http.Client, rawURL string) (RpkYaml, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("invalid rpk.yaml URL: %v", err)
+	}
+	name := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to create request for %s: %v", name, err)
+	}
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		// A *url.Error includes the full URL, query and all; we only
+		// report the redacted name.
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return RpkYaml{}, fmt.Errorf("unable to fetch %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return RpkYaml{}, fmt.Errorf("unable to fetch %s: %s", name, resp.Status)
+	}
+	file, err := io.ReadAll(io.LimitReader(resp.Body, maxRpkYamlURLSize+1))
+	if err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to read %s: %w", name, err)
+	}
+	if len(file) > maxRpkYamlURLSize {
+		return RpkYaml{}, fmt.Errorf("unable to read %s: larger than %d bytes", name, maxRpkYamlURLSize)
+	}
+	return parseRpkYaml(file, name)
+}
+
+// parseRpkYaml decodes and migrates an rpk.yaml, using name in errors.
+func parseRpkYaml(file []byte, name string) (RpkYaml, error) {
+	var y RpkYaml
+	if err := yaml.Unmarshal(file, &y); err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to yaml decode %s: %v", name, err)
+	}
+	unversioned := isUnversionedRpkYaml(file)
+	switch {
+	case y.Version < 1 && !unversioned:
+		return RpkYaml{}, fmt.Errorf("%s is not in the expected rpk.yaml format", name)
+	case y.Version > currentRpkYAMLVersion:
+		return RpkYaml{}, fmt.Errorf("%s is using a newer rpk.yaml format (version %d) than we understand (up to version %d), please upgrade rpk", name, y.Version, currentRpkYAMLVersion)
+	}
+	y.Version = currentRpkYAMLVersion
+	if err := y.trimNames(); err != nil {
+		return RpkYaml{}, fmt.Errorf("unable to load %s: %w", name, err)
+	}
+	if unversioned {
+		y.migrateUnversioned()
+	}
+	return y, nil
+}
+
 // FileLocation returns the path to this rpk.yaml, whether it exists or not.
 func (y *RpkYaml) FileLocation() string {
 	return y.fileLocation
 }
 
 // Write writes the configuration at the previously loaded path, or the default
-// path.
+// path. This is a no-op if the configuration is unchanged from the loaded file,
+// or if no file was loaded and the configuration is the in-memory default.
+// If globals.journal is enabled, each write appends a summary of what changed
+// to the journal file at JournalPath.
+//
+// YAML anchors and aliases in a hand-written rpk.yaml are expanded on load,
+// such that every alias is an independent copy, and are not preserved: if
+// the file is rewritten, each alias is written out in full.
 func (y *RpkYaml) Write(fs afero.Fs) error {
-	if y.isTheSameAsRawFile() {
+	_, err := y.WriteIfChanged(fs)
+	return err
+}
+
+// WriteSorted is Write, but profiles and cloud auths are written sorted by
+// name, as in ProfileNames and AuthNames, so that the file is the same
+// regardless of the order profiles and auths were added in. This is meant for
+// rpk.yaml files that are kept in version control. The order of profiles and
+// auths in y is unchanged.
+func (y *RpkYaml) WriteSorted(fs afero.Fs) error {
+	dup := y.Clone()
+	sort.SliceStable(dup.Profiles, func(i, j int) bool { return lessName(dup.Profiles[i].Name, dup.Profiles[j].Name) })
+	sort.SliceStable(dup.CloudAuths, func(i, j int) bool { return lessName(dup.CloudAuths[i].Name, dup.CloudAuths[j].Name) })
+	return dup.Write(fs)
+}
+
+// WriteIfChanged is Write, but also returns whether the file was written,
+// which is false if the configuration is unchanged. As in WriteAt, the file is
+// locked while writing; the lock is also held while journaling, so that
+// concurrent writers are journaled against the file they replaced.
+func (y *RpkYaml) WriteIfChanged(fs afero.Fs) (bool, error) {
+	if y.isTheSameAsRawFile() || y.isTheSameAsDefault() {
+		return false, nil
+	}
+	location, err := y.writeLocation()
+	if err != nil {
+		return false, err
+	}
+	unlock, err := rpkos.LockExclusive(fs, location+".lock")
+	if err != nil {
+		return false, fmt.Errorf("unable to lock %s for writing: %v", location, err)
+	}
+	defer unlock()
+	b, err := y.marshalForWrite()
+	if err != nil {
+		return false, fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	prior, journal := y.readJournalPrior(fs, location)
+	if err := y.replaceFile(fs, location, b); err != nil {
+		return false, err
+	}
+	if journal {
+		y.appendJournal(fs, location, prior)
+	}
+	return true, nil
+}
+
+// WriteWithBackup is like Write, but first copies the existing file, if any,
+// to the same path with a .bak suffix, readable only by its owner. If the
+// file cannot be replaced, the previous backup is restored so that the file
+// and its backup are both left as they were.
+func (y *RpkYaml) WriteWithBackup(fs afero.Fs) error {
+	if y.isTheSameAsRawFile() || y.isTheSameAsDefault() {
 		return nil
 	}
-	location := y.fileLocation
-	if location == "" {
-		def, err := DefaultRpkYamlPath()
-		if err != nil {
+	location, err := y.writeLocation()
+	if err != nil {
+		return err
+	}
+	unlock, err := rpkos.LockExclusive(fs, location+".lock")
+	if err != nil {
+		return fmt.Errorf("unable to lock %s for writing: %v", location, err)
+	}
+	defer unlock()
+	b, err := y.marshalForWrite()
+	if err != nil {
+		return fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	prior, journal := y.readJournalPrior(fs, location)
+
+	raw, err := afero.ReadFile(fs, location)
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return fmt.Errorf("unable to read %s for backup: %v", location, err)
+		}
+		if err := y.replaceFile(fs, location, b); err != nil {
 			return err
 		}
-		location = def
+		if journal {
+			y.appendJournal(fs, location, prior)
+		}
+		return nil
+	}
+
+	bak := location + ".bak"
+	oldBak, err := afero.ReadFile(fs, bak)
+	hadBak := err == nil
+	if err := writeBackup(fs, bak, raw); err != nil {
+		return fmt.Errorf("unable to back up %s: %v", location, err)
+	}
+	if err := y.replaceFile(fs, location, b); err != nil {
+		var rollbackErr error
+		if hadBak {
+			rollbackErr = writeBackup(fs, bak, oldBak)
+		} else {
+			rollbackErr = fs.Remove(bak)
+		}
+		if rollbackErr != nil {
+			return fmt.Errorf("unable to write %s: %v; unable to restore %s: %v", location, err, bak, rollbackErr)
+		}
+		return fmt.Errorf("unable to write %s: %v", location, err)
+	}
+	if journal {
+		y.appendJournal(fs, location, prior)
+	}
+	return nil
+}
+
+// writeLocation returns the path Write writes to: the previously loaded path,
+// or the default path.
+func (y *RpkYaml) writeLocation() (string, error) {
+	if y.fileLocation != "" {
+		return y.fileLocation, nil
 	}
-	return y.WriteAt(fs, location)
+	return DefaultRpkYamlPath()
 }
 
-// WriteAt writes the configuration to the given path.
+// WouldChange returns whether Write would modify the file on disk, as well as
+// the bytes that Write would write. This does not write anything.
+func (y *RpkYaml) WouldChange(fs afero.Fs) (bool, []byte, error) {
+	b, err := y.marshalForWrite()
+	if err != nil {
+		return false, nil, fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	location, err := y.writeLocation()
+	if err != nil {
+		return false, nil, err
+	}
+	raw, err := afero.ReadFile(fs, location)
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return false, nil, err
+		}
+		return !y.isTheSameAsDefault(), b, nil
+	}
+	return !y.isTheSameAs(raw), b, nil
+}
+
+// stdout is where WriteAt writes if the path is "-"; tests replace it.
+var stdout io.Writer = os.Stdout
+
+// WriteAt writes the configuration to the given path. Concurrent writers
+// (including other rpk processes) are serialized with an exclusive lock on a
+// sidecar "<path>.lock" file. If path is "-", the configuration is written to
+// stdout instead, and fs is unused.
 func (y *RpkYaml) WriteAt(fs afero.Fs, path string) error {
-	b, err := yaml.Marshal(y)
+	if path == "-" {
+		if _, err := y.WriteTo(stdout); err != nil {
+			return fmt.Errorf("unable to write to stdout: %v", err)
+		}
+		return nil
+	}
+	unlock, err := rpkos.LockExclusive(fs, path+".lock")
+	if err != nil {
+		return fmt.Errorf("unable to lock %s for writing: %v", path, err)
+	}
+	defer unlock()
+	b, err := y.marshalForWrite()
 	if err != nil {
 		return fmt.Errorf("marshal error in loaded config, err: %s", err)
 	}
-	return rpkos.ReplaceFile(fs, path, b, 0o644)
+	return y.replaceFile(fs, path, b)
+}
+
+// marshalForWrite returns the yaml encoding of what is written for y, with
+// secrets encrypted if y is encrypted.
+func (y *RpkYaml) marshalForWrite() ([]byte, error) {
+	w, err := y.forWrite()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(w)
+}
+
+// replaceFile replaces path with b. New files are created with mode 0o644, or
+// 0o600 if the rpk.yaml contains any secret (a SASL password or a cloud auth
+// token or client secret). If the rpk.yaml contains secrets, an existing file
+// that is readable by group or others is restricted to its owner; otherwise,
+// the mode of an existing file is preserved.
+func (y *RpkYaml) replaceFile(fs afero.Fs, path string, b []byte) error {
+	if !y.hasSecrets() {
+		return rpkos.ReplaceFile(fs, path, b, 0o644)
+	}
+	if err := rpkos.ReplaceFile(fs, path, b, 0o600); err != nil {
+		return err
+	}
+	stat, err := fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("unable to stat %s: %v", path, err)
+	}
+	if perm := stat.Mode().Perm(); perm&0o077 != 0 {
+		if err := fs.Chmod(path, perm&^0o077); err != nil {
+			return fmt.Errorf("unable to restrict the permissions of %s, which contains secrets: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// writeBackup replaces the backup at path with b, the contents of a prior
+// rpk.yaml. The backup is always restricted to its owner: whether b contains
+// secrets is unrelated to whether the configuration being written does.
+func writeBackup(fs afero.Fs, path string, b []byte) error {
+	if err := rpkos.ReplaceFile(fs, path, b, 0o600); err != nil {
+		return err
+	}
+	if err := fs.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("unable to restrict the permissions of %s: %v", path, err)
+	}
+	return nil
+}
+
+// WriteTo writes the yaml encoded configuration to w, satisfying io.WriterTo.
+// As when writing to a file, secrets are encrypted if y is encrypted. Pair
+// this with Redacted to safely dump a configuration.
+func (y *RpkYaml) WriteTo(w io.Writer) (int64, error) {
+	b, err := y.marshalForWrite()
+	if err != nil {
+		return 0, fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ToJSON returns the rpk.yaml encoded as JSON. The JSON has exactly the
+// structure of the YAML encoding, including omitted empty fields and
+// encrypted secrets, because we encode through YAML first.
+func (y *RpkYaml) ToJSON() ([]byte, error) {
+	b, err := y.marshalForWrite()
+	if err != nil {
+		return nil, fmt.Errorf("marshal error in loaded config, err: %s", err)
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("unable to decode marshaled config: %v", err)
+	}
+	return json.Marshal(m)
+}
+
+// MarshalJSON encodes the rpk.yaml as JSON, with any unknown fields from Extra
+// inlined alongside the known fields, as they are in YAML.
+func (y RpkYaml) MarshalJSON() ([]byte, error) {
+	type noMethods RpkYaml
+	return marshalJSONInline(noMethods(y), y.Extra)
+}
+
+// MarshalJSON encodes the profile as JSON, with any unknown fields from Extra
+// inlined alongside the known fields, as they are in YAML.
+func (p RpkProfile) MarshalJSON() ([]byte, error) {
+	type noMethods RpkProfile
+	return marshalJSONInline(noMethods(p), p.Extra)
+}
+
+// MarshalJSON encodes the cloud auth as JSON, with any unknown fields from
+// Extra inlined alongside the known fields, as they are in YAML.
+func (a RpkCloudAuth) MarshalJSON() ([]byte, error) {
+	type noMethods RpkCloudAuth
+	return marshalJSONInline(noMethods(a), a.Extra)
+}
+
+// marshalJSONInline encodes v as a JSON object and adds the fields in extra
+// that v does not already encode. encoding/json has no equivalent of the
+// yaml ",inline" map tag, so Extra fields are tagged json:"-" and are added
+// here instead.
+func marshalJSONInline(v any, extra map[string]any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, x := range extra {
+		if _, exists := m[k]; exists {
+			continue
+		}
+		raw, err := json.Marshal(x)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode unknown field %q: %v", k, err)
+		}
+		m[k] = raw
+	}
+	return json.Marshal(m)
 }
 
 // FullName returns "resource_group/cluster_name".
@@ -455,7 +2378,12 @@ func (d Duration) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
+// An empty string decodes to the zero Duration, i.e. "use the default".
 func (d *Duration) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		d.Duration = 0
+		return nil
+	}
 	var err error
 	d.Duration, err = time.ParseDuration(string(text))
 	return err
@@ -463,6 +2391,26 @@ func (d *Duration) UnmarshalText(text []byte) error {
 
 func (*Duration) YamlTypeNameForTest() string { return "duration" }
 
+// timestampNow returns the current time as a Timestamp, truncated to the
+// second precision of its encoding.
+func timestampNow() Timestamp {
+	return Timestamp{time.Now().UTC().Truncate(time.Second)}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.Time.Format(time.RFC3339)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	var err error
+	t.Time, err = time.Parse(time.RFC3339, string(text))
+	return err
+}
+
+func (*Timestamp) YamlTypeNameForTest() string { return "timestamp" }
+
 /////////////////////
 // GLOBALS GETTERS //
 /////////////////////
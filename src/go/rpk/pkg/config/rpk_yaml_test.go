@@ -10,9 +10,30 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/cloudapi"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestRpkYamlVersion(t *testing.T) {
@@ -25,11 +46,2472 @@ func TestRpkYamlVersion(t *testing.T) {
 	shastr := hex.EncodeToString(sha[:])
 
 	const (
-		v5sha = "74f0b320006aac52cb75af3d5c658f81decf3e40fe1e92f3eb1d59f5c47ab5f9" // 24-04-29
+		v29sha = "e153c5c468d8c95c411fc9665c98f30e12768bc61a57ab70ef1536b9cb32763f" // 26-10-14
 	)
 
-	if shastr != v5sha {
-		t.Errorf("rpk.yaml type shape has changed (got sha %s != exp %s, if fields were reordered, update the valid v3 sha, otherwise bump the rpk.yaml version number", shastr, v5sha)
+	if shastr != v29sha {
+		t.Errorf("rpk.yaml type shape has changed (got sha %s != exp %s, if fields were reordered, update the valid v3 sha, otherwise bump the rpk.yaml version number", shastr, v29sha)
 		t.Errorf("current shape:\n%s\n", s)
 	}
 }
+
+func TestDefaultRpkYamlPathEnv(t *testing.T) {
+	t.Setenv(EnvRpkYamlPath, "")
+	def, err := DefaultRpkYamlPath()
+	require.NoError(t, err)
+	require.True(t, filepath.IsAbs(def))
+
+	t.Setenv(EnvRpkYamlPath, "/etc/rpk/rpk.yaml")
+	path, err := DefaultRpkYamlPath()
+	require.NoError(t, err)
+	require.Equal(t, "/etc/rpk/rpk.yaml", path)
+
+	t.Setenv(EnvRpkYamlPath, "relative/rpk.yaml")
+	path, err = DefaultRpkYamlPath()
+	require.NoError(t, err)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(wd, "relative/rpk.yaml"), path)
+
+	t.Setenv(EnvRpkYamlPath, "")
+	unset, err := DefaultRpkYamlPath()
+	require.NoError(t, err)
+	require.Equal(t, def, unset)
+}
+
+func TestRpkYamlHasProfileHasAuth(t *testing.T) {
+	y := RpkYaml{
+		Profiles: []RpkProfile{
+			{Name: "foo"},
+			{Name: ""},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "bar"},
+			{Name: ""},
+		},
+	}
+	require.True(t, y.HasProfile("foo"))
+	require.False(t, y.HasProfile("bar"))
+	require.False(t, y.HasProfile(""))
+
+	require.True(t, y.HasAuth("bar"))
+	require.False(t, y.HasAuth("foo"))
+	require.False(t, y.HasAuth(""))
+
+	var nilYaml *RpkYaml
+	require.False(t, nilYaml.HasProfile("foo"))
+	require.False(t, nilYaml.HasAuth("bar"))
+}
+
+func TestRpkYamlSetCurrentProfile(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{
+			{Name: "foo"},
+			{Name: "bar"},
+		},
+	}
+
+	p, err := y.SetCurrentProfile("bar")
+	require.NoError(t, err)
+	require.Equal(t, "bar", y.CurrentProfile)
+	require.Same(t, &y.Profiles[1], p)
+
+	for _, name := range []string{"missing", ""} {
+		p, err = y.SetCurrentProfile(name)
+		require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+		require.Nil(t, p)
+		require.Equal(t, "bar", y.CurrentProfile)
+	}
+}
+
+func TestRpkYamlResolveCurrent(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile:        "foo",
+		CurrentCloudAuthOrgID: "org",
+		CurrentCloudAuthKind:  CloudAuthSSO,
+		Profiles:              []RpkProfile{{Name: "foo"}},
+		CloudAuths:            []RpkCloudAuth{{Name: "auth", OrgID: "org", Kind: CloudAuthSSO}},
+	}
+
+	p, err := y.ResolveCurrentProfile()
+	require.NoError(t, err)
+	require.Same(t, &y.Profiles[0], p)
+	a, err := y.ResolveCurrentAuth()
+	require.NoError(t, err)
+	require.Same(t, &y.CloudAuths[0], a)
+
+	dangling := y
+	dangling.CurrentProfile = "missing"
+	dangling.CurrentCloudAuthKind = CloudAuthClientCredentials
+	_, err = dangling.ResolveCurrentProfile()
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+	_, err = dangling.ResolveCurrentAuth()
+	require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+
+	var empty RpkYaml
+	_, err = empty.ResolveCurrentProfile()
+	require.True(t, errors.Is(err, ErrNoCurrentProfile), "got err %v", err)
+
+	implicit := RpkYaml{Profiles: []RpkProfile{{Name: "only"}}}
+	p, err = implicit.ResolveCurrentProfile()
+	require.NoError(t, err)
+	require.Same(t, &implicit.Profiles[0], p)
+
+	ambiguous := RpkYaml{Profiles: []RpkProfile{{Name: "foo"}, {Name: "bar"}}}
+	_, err = ambiguous.ResolveCurrentProfile()
+	require.True(t, errors.Is(err, ErrNoCurrentProfile), "got err %v", err)
+	require.Contains(t, err.Error(), "bar, foo")
+	_, err = empty.ResolveCurrentAuth()
+	require.True(t, errors.Is(err, ErrNoCurrentAuth), "got err %v", err)
+}
+
+func TestRpkProfileDefaults(t *testing.T) {
+	var p RpkProfile
+	require.NoError(t, yaml.Unmarshal([]byte("name: foo\n"), &p))
+	require.Equal(t, RpkProfileDefaults{}, p.ProfileDefaults)
+	require.Empty(t, p.DefaultConsumerGroup())
+	require.Empty(t, (*RpkProfile)(nil).DefaultConsumerGroup())
+
+	raw, err := yaml.Marshal(p)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "defaults")
+
+	require.NoError(t, yaml.Unmarshal([]byte(`name: foo
+defaults:
+    topic_prefix: team-a.
+    partitions: 6
+    group: team-a-consumers
+`), &p))
+	require.Equal(t, RpkProfileDefaults{TopicPrefix: "team-a.", Partitions: 6, Group: "team-a-consumers"}, p.ProfileDefaults)
+	require.Equal(t, "team-a-consumers", p.DefaultConsumerGroup())
+
+	raw, err = yaml.Marshal(p)
+	require.NoError(t, err)
+	var roundTrip RpkProfile
+	require.NoError(t, yaml.Unmarshal(raw, &roundTrip))
+	require.Equal(t, p.ProfileDefaults, roundTrip.ProfileDefaults)
+	require.Equal(t, "team-a-consumers", roundTrip.DefaultConsumerGroup())
+}
+
+func TestRpkProfileAnnotations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/rpk.yaml", []byte(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      annotations:
+        provisioned-by: terraform
+        runbook: |
+            1. Scale the node pool.
+            2. Run 'rpk cluster health'.
+`, currentRpkYAMLVersion)), 0o644))
+	exp := map[string]string{
+		"provisioned-by": "terraform",
+		"runbook":        "1. Scale the node pool.\n2. Run 'rpk cluster health'.\n",
+	}
+
+	y, _, err := LoadRpkYamlOrDefault(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, exp, y.Profile("foo").Annotations)
+
+	y.Profile("foo").Description = "changed"
+	require.NoError(t, y.Write(fs))
+	y, _, err = LoadRpkYamlOrDefault(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, exp, y.Profile("foo").Annotations)
+
+	// Copies do not share annotations.
+	dup := y.Profile("foo").deepCopy()
+	dup.Annotations["runbook"] = "none"
+	require.Equal(t, exp, y.Profile("foo").Annotations)
+}
+
+func TestRpkYamlProfilesWithLabel(t *testing.T) {
+	y := RpkYaml{
+		Profiles: []RpkProfile{
+			{Name: "foo", Labels: map[string]string{"team": "a", "env": "prod"}},
+			{Name: "bar", Labels: map[string]string{"team": "b", "env": "prod"}},
+			{Name: "biz", Labels: map[string]string{"team": "a"}},
+			{Name: "baz"},
+		},
+	}
+	names := func(ps []*RpkProfile) []string {
+		var names []string
+		for _, p := range ps {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"foo", "biz"}, names(y.ProfilesWithLabel("team", "a")))
+	require.Equal(t, []string{"foo", "bar"}, names(y.ProfilesWithLabel("env", "")))
+	require.Empty(t, y.ProfilesWithLabel("team", "c"))
+	require.Empty(t, y.ProfilesWithLabel("owner", ""))
+
+	matches := y.ProfilesWithLabel("team", "b")
+	require.Len(t, matches, 1)
+	require.Same(t, &y.Profiles[1], matches[0])
+}
+
+func TestRpkYamlUpsertProfile(t *testing.T) {
+	created := Timestamp{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	y := RpkYaml{
+		CurrentProfile: "bar",
+		Profiles: []RpkProfile{
+			{Name: "foo", CreatedAt: created},
+			{Name: "bar", Aliases: []string{"b"}},
+		},
+	}
+	upsert := func(p RpkProfile) string {
+		change, err := y.UpsertProfile(p)
+		require.NoError(t, err)
+		return change
+	}
+
+	require.Equal(t, ChangeModified, upsert(RpkProfile{Name: "foo", Description: "updated"}))
+	require.Equal(t, []string{"foo", "bar"}, []string{y.Profiles[0].Name, y.Profiles[1].Name})
+	require.Equal(t, "updated", y.Profiles[0].Description)
+	require.Equal(t, created, y.Profiles[0].CreatedAt)
+
+	require.Equal(t, ChangeAdded, upsert(RpkProfile{Name: "biz"}))
+	require.Equal(t, "biz", y.Profiles[0].Name)
+	require.False(t, y.Profiles[0].CreatedAt.IsZero())
+	require.Equal(t, "bar", y.CurrentProfile)
+
+	require.Equal(t, ChangeModified, upsert(RpkProfile{Name: "biz", Description: "again"}))
+	require.Len(t, y.Profiles, 3)
+	require.NoError(t, y.Validate(), "upserting resulted in duplicate profiles")
+
+	// Upserting an alias does not overwrite the aliased profile.
+	_, err := y.UpsertProfile(RpkProfile{Name: "b", Description: "shadow"})
+	require.ErrorIs(t, err, ErrDuplicateProfile)
+	require.Len(t, y.Profiles, 3)
+	require.Equal(t, "bar", y.Profile("b").Name)
+	require.Equal(t, "", y.Profile("bar").Description)
+}
+
+func TestRpkYamlGetOrCreateProfile(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile: "foo",
+		Profiles:       []RpkProfile{{Name: "foo"}, {Name: "bar", Description: "existing", Aliases: []string{"b"}}},
+	}
+	getOrCreate := func(name string) *RpkProfile {
+		p, err := y.GetOrCreateProfile(name)
+		require.NoError(t, err)
+		return p
+	}
+
+	p := getOrCreate("bar")
+	require.Same(t, &y.Profiles[1], p)
+	require.Equal(t, "existing", p.Description)
+	require.Len(t, y.Profiles, 2)
+
+	p = getOrCreate("biz")
+	require.Len(t, y.Profiles, 3)
+	require.Same(t, &y.Profiles[2], p)
+	require.Equal(t, "biz", p.Name)
+	require.False(t, p.CreatedAt.IsZero())
+	require.Equal(t, "foo", y.CurrentProfile)
+
+	p.Description = "created"
+	require.Equal(t, "created", y.Profile("biz").Description)
+	require.Same(t, p, getOrCreate("biz"))
+
+	_, err := y.GetOrCreateProfile("b")
+	require.ErrorIs(t, err, ErrDuplicateProfile)
+	require.Len(t, y.Profiles, 3)
+}
+
+func TestRpkYamlFindProfiles(t *testing.T) {
+	y := RpkYaml{Profiles: []RpkProfile{
+		{Name: "prod-us"},
+		{Name: "staging"},
+		{Name: "Prod-EU"},
+	}}
+	names := func(ps []*RpkProfile) []string {
+		var names []string
+		for _, p := range ps {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"prod-us", "Prod-EU"}, names(y.FindProfiles("PROD")))
+
+	unique := y.FindProfiles("stag")
+	require.Len(t, unique, 1)
+	require.Same(t, &y.Profiles[1], unique[0])
+
+	require.Empty(t, y.FindProfiles("dev"))
+}
+
+func TestRpkYamlSelectProfiles(t *testing.T) {
+	y := RpkYaml{Profiles: []RpkProfile{
+		{Name: "prod-us"},
+		{Name: "staging"},
+		{Name: "prod-eu"},
+		{Name: "Prod-old"},
+	}}
+	names := func(ps []*RpkProfile) []string {
+		var names []string
+		for _, p := range ps {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	ps, err := y.SelectProfiles("prod-*")
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod-us", "prod-eu"}, names(ps))
+	require.Same(t, &y.Profiles[2], ps[1])
+
+	ps, err = y.SelectProfiles("prod-old")
+	require.NoError(t, err)
+	require.Empty(t, ps, "names are matched case-sensitively")
+
+	ps, err = y.SelectProfiles("[ps]*")
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod-us", "staging", "prod-eu"}, names(ps))
+
+	ps, err = y.SelectProfiles("dev-*")
+	require.NoError(t, err)
+	require.Empty(t, ps)
+
+	_, err = y.SelectProfiles("prod-[")
+	require.ErrorIs(t, err, path.ErrBadPattern)
+	_, err = (&RpkYaml{}).SelectProfiles("prod-[")
+	require.ErrorIs(t, err, path.ErrBadPattern, "malformed patterns fail without profiles")
+}
+
+func TestRpkYamlProfilesForCluster(t *testing.T) {
+	y := RpkYaml{Profiles: []RpkProfile{
+		{Name: "a", FromCloud: true, CloudCluster: RpkCloudCluster{ResourceGroup: "rg", ClusterName: "prod"}},
+		{Name: "b", FromCloud: true, CloudCluster: RpkCloudCluster{ResourceGroup: "rg", ClusterName: "dev"}},
+		{Name: "c", FromCloud: true, CloudCluster: RpkCloudCluster{Namespace: "rg", ClusterName: "prod"}},
+		{Name: "d", FromCloud: true, CloudCluster: RpkCloudCluster{ResourceGroup: "other", ClusterName: "prod"}},
+		{Name: "local"},
+	}}
+
+	matches := y.ProfilesForCluster("rg", "prod")
+	require.Len(t, matches, 2)
+	require.Same(t, &y.Profiles[0], matches[0])
+	require.Same(t, &y.Profiles[2], matches[1])
+
+	require.Empty(t, y.ProfilesForCluster("rg", ""), "resource group alone matched")
+	require.Empty(t, y.ProfilesForCluster("rg", "missing"))
+
+	local := RpkYaml{Profiles: []RpkProfile{{Name: "foo"}, {Name: "bar"}}}
+	require.Empty(t, local.ProfilesForCluster("", ""))
+}
+
+func TestRpkYamlProfileForBroker(t *testing.T) {
+	y := RpkYaml{
+		Profiles: []RpkProfile{
+			{Name: "none"},
+			{Name: "local", KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9093", "localhost"}}},
+			{Name: "prod", KafkaAPI: RpkKafkaAPI{Brokers: []string{"broker-0.prod:9092", "broker-1.prod:29092"}}},
+			{Name: "prod-dup", KafkaAPI: RpkKafkaAPI{Brokers: []string{"broker-1.prod:29092"}}},
+		},
+	}
+
+	for _, test := range []struct {
+		name string
+		addr string
+		exp  string
+	}{
+		{name: "exact match", addr: "broker-1.prod:29092", exp: "prod"},
+		{name: "default port in addr", addr: "broker-0.prod", exp: "prod"},
+		{name: "default port in profile", addr: "localhost:9092", exp: "local"},
+		{name: "case insensitive host", addr: "Broker-0.PROD:9092", exp: "prod"},
+		{name: "port mismatch", addr: "127.0.0.1", exp: ""},
+		{name: "no match", addr: "broker-2.prod:9092", exp: ""},
+		{name: "empty", addr: "", exp: ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p, ok := y.ProfileForBroker(test.addr)
+			if test.exp == "" {
+				require.False(t, ok)
+				require.Nil(t, p)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, test.exp, p.Name)
+		})
+	}
+}
+
+func TestRpkYamlNames(t *testing.T) {
+	exp := []string{"alpha", "Bar", "bar", "biz", "Zed"}
+	for _, order := range [][]string{
+		{"Zed", "bar", "alpha", "biz", "Bar"},
+		{"biz", "Bar", "Zed", "alpha", "bar"},
+		{"alpha", "Bar", "bar", "biz", "Zed"},
+	} {
+		var y RpkYaml
+		for _, name := range order {
+			y.PushProfile(RpkProfile{Name: name})
+			y.PushNewAuth(RpkCloudAuth{Name: name})
+		}
+		require.Equal(t, exp, y.ProfileNames())
+		require.Equal(t, exp, y.AuthNames())
+		var sorted []string
+		for _, p := range y.SortedProfiles() {
+			sorted = append(sorted, p.Name)
+		}
+		require.Equal(t, exp, sorted)
+	}
+
+	var empty RpkYaml
+	require.Empty(t, empty.ProfileNames())
+	require.Empty(t, empty.SortedProfiles())
+	require.Empty(t, empty.AuthNames())
+
+	// Duplicate names are each returned, rather than the first twice.
+	dups := RpkYaml{Profiles: []RpkProfile{
+		{Name: "foo", Description: "first"},
+		{Name: "bar"},
+		{Name: "foo", Description: "second"},
+	}}
+	sorted := dups.SortedProfiles()
+	require.Len(t, sorted, 3)
+	require.Equal(t, "bar", sorted[0].Name)
+	require.Equal(t, "first", sorted[1].Description)
+	require.Equal(t, "second", sorted[2].Description)
+	require.Same(t, &dups.Profiles[2], sorted[2])
+}
+
+func TestRpkProfileReadOnly(t *testing.T) {
+	var p RpkProfile
+	require.NoError(t, yaml.Unmarshal([]byte("name: foo\n"), &p))
+	require.False(t, p.IsReadOnly())
+
+	p.ReadOnly = true
+	raw, err := yaml.Marshal(p)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "read_only: true")
+
+	var got RpkProfile
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.True(t, got.IsReadOnly())
+
+	var nilProfile *RpkProfile
+	require.False(t, nilProfile.IsReadOnly())
+}
+
+func TestRpkProfileTimestamps(t *testing.T) {
+	// Old configs have no timestamps.
+	var y RpkYaml
+	require.NoError(t, yaml.Unmarshal([]byte(`profiles:
+    - name: old
+`), &y))
+	require.True(t, y.Profiles[0].CreatedAt.IsZero())
+	require.True(t, y.Profiles[0].LastUsedAt.IsZero())
+	raw, err := yaml.Marshal(y.Profiles[0])
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "created_at")
+	require.NotContains(t, string(raw), "last_used_at")
+
+	before := time.Now().Add(-time.Second)
+	y.PushProfile(RpkProfile{Name: "new"})
+	created := y.Profile("new").CreatedAt
+	require.True(t, created.After(before), "created at %v is not after %v", created, before)
+	require.True(t, y.Profile("new").LastUsedAt.IsZero())
+
+	// An existing creation time is kept.
+	stamp := Timestamp{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	y.PushProfile(RpkProfile{Name: "imported", CreatedAt: stamp})
+	require.Equal(t, stamp, y.Profile("imported").CreatedAt)
+
+	p, err := y.SetCurrentProfile("old")
+	require.NoError(t, err)
+	require.True(t, p.LastUsedAt.After(before), "last used at %v is not after %v", p.LastUsedAt, before)
+	require.True(t, p.CreatedAt.IsZero())
+
+	// Timestamps are RFC3339 in yaml and round trip.
+	raw, err = yaml.Marshal(y.Profile("imported"))
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "created_at: \"2024-01-02T03:04:05Z\"")
+	var got RpkProfile
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.True(t, stamp.Equal(got.CreatedAt.Time))
+}
+
+func TestRpkProfileTouch(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	initial := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:     "foo",
+			KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+		}},
+	}
+	require.NoError(t, initial.WriteAt(fs, "/etc/rpk/rpk.yaml"))
+	contents, err := afero.ReadFile(fs, "/etc/rpk/rpk.yaml")
+	require.NoError(t, err)
+	load := func() *RpkYaml {
+		cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+		require.NoError(t, err)
+		y, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		return y
+	}
+	// onDisk decodes the file without loading it, which would touch it.
+	onDisk := func() *RpkYaml {
+		raw, err := afero.ReadFile(fs, "/etc/rpk/rpk.yaml")
+		require.NoError(t, err)
+		var y RpkYaml
+		require.NoError(t, yaml.Unmarshal(raw, &y))
+		return &y
+	}
+
+	before := time.Now().Add(-time.Second)
+	(*RpkProfile)(nil).Touch()
+	p := RpkProfile{Name: "bar"}
+	p.Touch()
+	require.True(t, p.LastUsedAt.After(before), "last used at %v is not after %v", p.LastUsedAt, before)
+
+	// Loading touches the current profile, but a touch alone does not
+	// rewrite the file.
+	y := load()
+	touched := y.Profile("foo").LastUsedAt
+	require.True(t, touched.After(before), "last used at %v is not after %v", touched, before)
+	require.NoError(t, y.Write(fs))
+	raw, err := afero.ReadFile(fs, "/etc/rpk/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, string(contents), string(raw))
+
+	// The touch from loading is persisted alongside other changes and
+	// round trips.
+	y = load()
+	touched = y.Profile("foo").LastUsedAt
+	y.Profile("foo").Description = "modified"
+	require.NoError(t, y.Write(fs))
+	saved := onDisk().Profile("foo")
+	require.Equal(t, "modified", saved.Description)
+	require.True(t, touched.Equal(saved.LastUsedAt.Time))
+
+	// WriteAt persists a touch on its own.
+	reloaded := load()
+	reloaded.Profile("foo").LastUsedAt = Timestamp{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	require.NoError(t, reloaded.WriteAt(fs, "/etc/rpk/rpk.yaml"))
+	require.Equal(t, 2024, onDisk().Profile("foo").LastUsedAt.Year())
+}
+
+func TestRpkYamlRenameProfile(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{
+			{Name: "foo"},
+			{Name: "bar"},
+		},
+	}
+
+	err := y.RenameProfile("missing", "biz")
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+
+	err = y.RenameProfile("foo", "bar")
+	require.True(t, errors.Is(err, ErrDuplicateProfile), "got err %v", err)
+
+	require.NoError(t, y.RenameProfile("foo", "biz"))
+	require.Equal(t, "biz", y.CurrentProfile)
+	require.Nil(t, y.Profile("foo"))
+	require.NotNil(t, y.Profile("biz"))
+
+	require.NoError(t, y.RenameProfile("bar", "baz"))
+	require.Equal(t, "biz", y.CurrentProfile)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, y.WriteAt(fs, "/rpk.yaml"))
+	raw, err := afero.ReadFile(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	var got RpkYaml
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.Equal(t, "biz", got.CurrentProfile)
+	require.NotNil(t, got.Profile("biz"))
+	require.NotNil(t, got.Profile("baz"))
+}
+
+func TestRpkYamlDeleteProfile(t *testing.T) {
+	mk := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile: "bar",
+			Profiles: []RpkProfile{
+				{Name: "foo"},
+				{Name: "bar"},
+				{Name: "biz"},
+			},
+		}
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		y := mk()
+		err := y.DeleteProfile("missing")
+		require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+		require.Len(t, y.Profiles, 3)
+	})
+
+	t.Run("current", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.DeleteProfile("bar"))
+		require.Nil(t, y.Profile("bar"))
+		require.Len(t, y.Profiles, 2)
+		// No profile was ever used: the first by name is selected.
+		require.Equal(t, "biz", y.CurrentProfile)
+	})
+
+	t.Run("current selects most recently used", func(t *testing.T) {
+		at := func(sec int64) Timestamp { return Timestamp{time.Unix(sec, 0).UTC()} }
+		y := RpkYaml{
+			CurrentProfile: "cur",
+			Profiles: []RpkProfile{
+				{Name: "cur", LastUsedAt: at(500)},
+				{Name: "old", LastUsedAt: at(100)},
+				{Name: "recent", LastUsedAt: at(300)},
+				{Name: "never"},
+			},
+		}
+		require.NoError(t, y.DeleteProfile("cur"))
+		require.Equal(t, "recent", y.CurrentProfile)
+		require.NoError(t, y.DeleteProfile("recent"))
+		require.Equal(t, "old", y.CurrentProfile)
+	})
+
+	t.Run("current tiebreak is alphabetical", func(t *testing.T) {
+		used := Timestamp{time.Unix(300, 0).UTC()}
+		y := RpkYaml{
+			CurrentProfile: "cur",
+			Profiles: []RpkProfile{
+				{Name: "cur", LastUsedAt: used},
+				{Name: "zeta", LastUsedAt: used},
+				{Name: "Beta", LastUsedAt: used},
+				{Name: "alpha"},
+				{Name: "gamma", LastUsedAt: used},
+			},
+		}
+		require.NoError(t, y.DeleteProfile("cur"))
+		require.Equal(t, "Beta", y.CurrentProfile)
+	})
+
+	t.Run("not current", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.DeleteProfile("biz"))
+		require.Nil(t, y.Profile("biz"))
+		require.Len(t, y.Profiles, 2)
+		require.Equal(t, "bar", y.CurrentProfile)
+	})
+
+	t.Run("last", func(t *testing.T) {
+		y := RpkYaml{
+			CurrentProfile: "foo",
+			Profiles:       []RpkProfile{{Name: "foo"}},
+		}
+		require.NoError(t, y.DeleteProfile("foo"))
+		require.Empty(t, y.Profiles)
+		require.Equal(t, "", y.CurrentProfile)
+	})
+}
+
+func TestRpkYamlDeleteAuth(t *testing.T) {
+	mk := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile:        "foo",
+			CurrentCloudAuthOrgID: "org1",
+			CurrentCloudAuthKind:  CloudAuthSSO,
+			Profiles: []RpkProfile{
+				{Name: "foo", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org1", AuthKind: CloudAuthSSO}},
+				{Name: "bar", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org1", AuthKind: CloudAuthSSO}},
+				{Name: "biz", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org2", AuthKind: CloudAuthSSO}},
+			},
+			CloudAuths: []RpkCloudAuth{
+				{Name: "a1", OrgID: "org1", Kind: CloudAuthSSO},
+				{Name: "a2", OrgID: "org2", Kind: CloudAuthSSO},
+				{Name: "a3", OrgID: "org3", Kind: CloudAuthClientCredentials},
+			},
+		}
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		y := mk()
+		err := y.DeleteAuth("missing", false)
+		require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+	})
+
+	t.Run("in use", func(t *testing.T) {
+		y := mk()
+		err := y.DeleteAuth("a1", false)
+		require.True(t, errors.Is(err, ErrAuthInUse), "got err %v", err)
+		require.Contains(t, err.Error(), "bar, foo")
+		require.Len(t, y.CloudAuths, 3)
+	})
+
+	t.Run("force", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.DeleteAuth("a1", true))
+		require.Len(t, y.CloudAuths, 2)
+		require.Nil(t, y.LookupAuth("org1", CloudAuthSSO))
+		for _, name := range []string{"foo", "bar"} {
+			p := y.Profile(name)
+			require.False(t, p.FromCloud)
+			require.Equal(t, "", p.CloudCluster.AuthOrgID)
+			require.Equal(t, "", p.CloudCluster.AuthKind)
+		}
+		require.True(t, y.Profile("biz").FromCloud)
+		require.Equal(t, "", y.CurrentCloudAuthOrgID)
+		require.Equal(t, "", y.CurrentCloudAuthKind)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		y := mk()
+		require.NoError(t, y.DeleteAuth("a3", false))
+		require.Len(t, y.CloudAuths, 2)
+		require.Equal(t, "org1", y.CurrentCloudAuthOrgID)
+	})
+}
+
+func TestRpkCloudAuthCloudURL(t *testing.T) {
+	var a RpkCloudAuth
+	require.NoError(t, yaml.Unmarshal([]byte("name: foo\n"), &a))
+	require.Equal(t, "", a.CloudURL)
+	require.Equal(t, cloudapi.ProdURL, a.CloudAPIURL())
+	raw, err := yaml.Marshal(a)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "cloud_url")
+
+	a.CloudURL = "https://cloud-api.staging.example.com"
+	raw, err = yaml.Marshal(a)
+	require.NoError(t, err)
+	var decoded RpkCloudAuth
+	require.NoError(t, yaml.Unmarshal(raw, &decoded))
+	require.Equal(t, a, decoded)
+	require.Equal(t, "https://cloud-api.staging.example.com", decoded.CloudAPIURL())
+
+	var nilAuth *RpkCloudAuth
+	require.Equal(t, cloudapi.ProdURL, nilAuth.CloudAPIURL())
+}
+
+func TestConfigCloudAPIURL(t *testing.T) {
+	load := func(t *testing.T, rpkYaml string) *Config {
+		fs := testfs.FromMap(map[string]testfs.Fmode{
+			"/rpk.yaml": testfs.RFile(fmt.Sprintf("version: %d\n%s", currentRpkYAMLVersion, rpkYaml)),
+		})
+		cfg, err := (&Params{ConfigFlag: "/rpk.yaml"}).Load(fs)
+		require.NoError(t, err)
+		return cfg
+	}
+	const auths = `cloud_auth:
+    - name: staging
+      organization: staging
+      org_id: staging-id
+      kind: sso
+      cloud_url: https://cloud-api.staging.example.com
+    - name: prod
+      organization: prod
+      org_id: prod-id
+      kind: sso
+`
+
+	t.Run("profile auth", func(t *testing.T) {
+		cfg := load(t, `current_profile: foo
+current_cloud_auth_org_id: prod-id
+current_cloud_auth_kind: sso
+profiles:
+    - name: foo
+      from_cloud: true
+      cloud_cluster:
+        auth_org_id: staging-id
+        auth_kind: sso
+`+auths)
+		require.Equal(t, "https://cloud-api.staging.example.com", cfg.CloudAPIURL())
+	})
+
+	t.Run("current auth", func(t *testing.T) {
+		cfg := load(t, `current_cloud_auth_org_id: staging-id
+current_cloud_auth_kind: sso
+`+auths)
+		require.Equal(t, "https://cloud-api.staging.example.com", cfg.CloudAPIURL())
+	})
+
+	t.Run("default", func(t *testing.T) {
+		cfg := load(t, `current_cloud_auth_org_id: prod-id
+current_cloud_auth_kind: sso
+`+auths)
+		require.Equal(t, cloudapi.ProdURL, cfg.CloudAPIURL())
+	})
+
+	t.Run("override", func(t *testing.T) {
+		t.Setenv("RPK_CLOUD_URL", "https://cloud-api.dev.example.com")
+		cfg := load(t, `current_cloud_auth_org_id: staging-id
+current_cloud_auth_kind: sso
+`+auths)
+		require.Equal(t, "https://cloud-api.dev.example.com", cfg.CloudAPIURL())
+	})
+}
+
+func TestRpkYamlRenameAuth(t *testing.T) {
+	y := RpkYaml{
+		CurrentCloudAuthOrgID: "org1",
+		CurrentCloudAuthKind:  CloudAuthSSO,
+		Profiles: []RpkProfile{
+			{Name: "foo", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org1", AuthKind: CloudAuthSSO}},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "sso", OrgID: "org1", Kind: CloudAuthSSO},
+			{Name: "creds", OrgID: "org1", Kind: CloudAuthClientCredentials},
+		},
+	}
+
+	err := y.RenameAuth("missing", "other")
+	require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+
+	err = y.RenameAuth("sso", "creds")
+	require.True(t, errors.Is(err, ErrDuplicateAuth), "got err %v", err)
+	require.Equal(t, []string{"creds", "sso"}, y.AuthNames())
+
+	require.NoError(t, y.RenameAuth("sso", "sso"))
+	require.NoError(t, y.RenameAuth("sso", "login"))
+	require.Equal(t, []string{"creds", "login"}, y.AuthNames())
+	require.Equal(t, "login", y.CurrentAuth().Name)
+	a, err := y.Profile("foo").ResolveAuth(&y)
+	require.NoError(t, err)
+	require.Equal(t, "login", a.Name)
+}
+
+func TestRpkYamlUpdateAuthToken(t *testing.T) {
+	y := RpkYaml{
+		CloudAuths: []RpkCloudAuth{
+			{Name: "other", AuthToken: "other-token"},
+			{Name: "auth", AuthToken: "old", RefreshToken: "old-refresh", ClientID: "id", ClientSecret: "secret"},
+		},
+	}
+
+	require.NoError(t, y.UpdateAuthToken("auth", "new", "new-refresh"))
+	require.Equal(t, RpkCloudAuth{Name: "auth", AuthToken: "new", RefreshToken: "new-refresh", ClientID: "id", ClientSecret: "secret"}, y.CloudAuths[1])
+
+	require.NoError(t, y.UpdateAuthToken("auth", "newer", ""))
+	require.Equal(t, "newer", y.CloudAuths[1].AuthToken)
+	require.Equal(t, "new-refresh", y.CloudAuths[1].RefreshToken)
+	require.Equal(t, "other-token", y.CloudAuths[0].AuthToken)
+
+	err := y.UpdateAuthToken("missing", "token", "")
+	require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+}
+
+func TestRpkYamlPruneUnusedAuths(t *testing.T) {
+	y := RpkYaml{
+		CurrentCloudAuthOrgID: "org2",
+		CurrentCloudAuthKind:  CloudAuthSSO,
+		Profiles: []RpkProfile{
+			{Name: "foo", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org1", AuthKind: CloudAuthSSO}},
+			{Name: "local"},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "orphan-kind", OrgID: "org1", Kind: CloudAuthClientCredentials},
+			{Name: "referenced", OrgID: "org1", Kind: CloudAuthSSO},
+			{Name: "orphan", OrgID: "org3", Kind: CloudAuthSSO},
+			{Name: "current", OrgID: "org2", Kind: CloudAuthSSO},
+		},
+	}
+
+	require.Equal(t, []string{"orphan-kind", "orphan"}, y.PruneUnusedAuths())
+	require.Equal(t, []string{"current", "referenced"}, y.AuthNames())
+	require.Equal(t, "org2", y.CurrentCloudAuthOrgID)
+	require.Empty(t, y.PruneUnusedAuths())
+}
+
+func TestRpkYamlRemoveAllCloud(t *testing.T) {
+	y := RpkYaml{
+		Version:               currentRpkYAMLVersion,
+		CurrentProfile:        "cloud",
+		CurrentCloudAuthOrgID: "org-id",
+		CurrentCloudAuthKind:  CloudAuthSSO,
+		CloudDefaults:         RpkCloudDefaults{ResourceGroup: "rg"},
+		Profiles: []RpkProfile{
+			{
+				Name:         "cloud",
+				FromCloud:    true,
+				CloudCluster: RpkCloudCluster{ClusterID: "id", ClusterName: "name", AuthOrgID: "org-id", AuthKind: CloudAuthSSO},
+				KafkaAPI:     RpkKafkaAPI{Brokers: []string{"cloud:9092"}},
+			},
+			{
+				Name:     "local",
+				KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+				AdminAPI: RpkAdminAPI{Addresses: []string{"127.0.0.1:9644"}},
+			},
+		},
+		CloudAuths: []RpkCloudAuth{{Name: "auth", Organization: "o", OrgID: "org-id", Kind: CloudAuthSSO, AuthToken: "secret-token"}},
+	}
+	y.RemoveAllCloud()
+
+	require.Equal(t, []string{"cloud", "local"}, y.ProfileNames())
+	require.Equal(t, []string{"cloud:9092"}, y.Profile("cloud").KafkaAPI.Brokers)
+	require.Equal(t, []string{"127.0.0.1:9644"}, y.Profile("local").AdminAPI.Addresses)
+
+	raw, err := yaml.Marshal(y)
+	require.NoError(t, err)
+	for _, s := range []string{"cloud_cluster", "cloud_defaults", "from_cloud: true", "org-id", "secret-token"} {
+		require.NotContains(t, string(raw), s)
+	}
+	var m map[string]any
+	require.NoError(t, yaml.Unmarshal(raw, &m))
+	require.Empty(t, m["cloud_auth"])
+	require.Empty(t, m["current_cloud_auth_org_id"])
+	require.Empty(t, m["current_cloud_auth_kind"])
+}
+
+func TestRpkYamlEqualIgnoringSecrets(t *testing.T) {
+	mk := func(pass, token, secret string) RpkYaml {
+		return RpkYaml{
+			Version:        currentRpkYAMLVersion,
+			CurrentProfile: "foo",
+			Profiles: []RpkProfile{{
+				Name: "foo",
+				KafkaAPI: RpkKafkaAPI{
+					Brokers: []string{"127.0.0.1:9092"},
+					SASL:    &SASL{User: "user", Password: pass, Mechanism: "SCRAM-SHA-256"},
+				},
+			}},
+			CloudAuths: []RpkCloudAuth{{
+				Name:         "auth",
+				OrgID:        "org",
+				Kind:         CloudAuthClientCredentials,
+				AuthToken:    token,
+				RefreshToken: token,
+				ClientID:     "id",
+				ClientSecret: secret,
+			}},
+		}
+	}
+
+	dev, prod := mk("dev", "dev-token", "dev-secret"), mk("prod", "prod-token", "")
+	require.True(t, dev.EqualIgnoringSecrets(prod))
+	require.True(t, prod.EqualIgnoringSecrets(dev))
+	require.Equal(t, "dev", dev.Profiles[0].KafkaAPI.SASL.Password, "EqualIgnoringSecrets modified its receiver")
+
+	prod.Profiles[0].KafkaAPI.Brokers = []string{"prod.example.com:9092"}
+	require.False(t, dev.EqualIgnoringSecrets(prod))
+
+	prod = mk("prod", "prod-token", "prod-secret")
+	prod.Profiles[0].KafkaAPI.SASL.User = "other"
+	require.False(t, dev.EqualIgnoringSecrets(prod))
+
+	prod = mk("prod", "prod-token", "prod-secret")
+	prod.CloudAuths[0].ClientID = "other"
+	require.False(t, dev.EqualIgnoringSecrets(prod))
+}
+
+func TestRpkProfileResolveAuth(t *testing.T) {
+	y := RpkYaml{
+		Profiles: []RpkProfile{
+			{
+				Name:         "cloud",
+				FromCloud:    true,
+				CloudCluster: RpkCloudCluster{AuthOrgID: "org", AuthKind: CloudAuthSSO},
+			},
+			{Name: "local"},
+			{
+				Name:         "dangling",
+				FromCloud:    true,
+				CloudCluster: RpkCloudCluster{AuthOrgID: "missing", AuthKind: CloudAuthSSO},
+			},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "other", OrgID: "org", Kind: CloudAuthClientCredentials},
+			{Name: "sso", OrgID: "org", Kind: CloudAuthSSO},
+		},
+	}
+
+	a, err := y.Profile("cloud").ResolveAuth(&y)
+	require.NoError(t, err)
+	require.Same(t, &y.CloudAuths[1], a)
+
+	_, err = y.Profile("local").ResolveAuth(&y)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+
+	_, err = y.Profile("dangling").ResolveAuth(&y)
+	require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+}
+
+func TestRpkCloudAuthSameCredentials(t *testing.T) {
+	sign := func(t *testing.T, sub string, exp time.Time) string {
+		tok := jwt.New()
+		tok.Set(jwt.SubjectKey, sub)
+		tok.Set(jwt.ExpirationKey, exp)
+		signed, err := jwt.Sign(tok, jwa.HS256, []byte("secret"))
+		require.NoError(t, err)
+		return string(signed)
+	}
+	now := time.Now()
+
+	for _, test := range []struct {
+		name string
+		a, b *RpkCloudAuth
+		exp  bool
+	}{
+		{
+			name: "same client credentials, different names",
+			a:    &RpkCloudAuth{Name: "imported", Organization: "a", OrgID: "a-id", ClientID: "id", ClientSecret: "secret"},
+			b:    &RpkCloudAuth{Name: "local", Organization: "b", OrgID: "b-id", ClientID: "id", ClientSecret: "secret", AuthToken: "other"},
+			exp:  true,
+		},
+		{
+			name: "same token subject",
+			a:    &RpkCloudAuth{Name: "sso-1", AuthToken: sign(t, "user@example.com", now.Add(time.Hour))},
+			b:    &RpkCloudAuth{Name: "sso-2", AuthToken: sign(t, "user@example.com", now.Add(2*time.Hour))},
+			exp:  true,
+		},
+		{
+			name: "same opaque token",
+			a:    &RpkCloudAuth{Name: "a", AuthToken: "opaque"},
+			b:    &RpkCloudAuth{Name: "b", AuthToken: "opaque"},
+			exp:  true,
+		},
+		{
+			name: "different client secret",
+			a:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret"},
+			b:    &RpkCloudAuth{ClientID: "id", ClientSecret: "rotated"},
+		},
+		{
+			name: "different client ID",
+			a:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret"},
+			b:    &RpkCloudAuth{ClientID: "other", ClientSecret: "secret"},
+		},
+		{
+			name: "client credentials versus token",
+			a:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret"},
+			b:    &RpkCloudAuth{AuthToken: sign(t, "id@clients", now.Add(time.Hour))},
+		},
+		{
+			name: "different token subject",
+			a:    &RpkCloudAuth{AuthToken: sign(t, "user@example.com", now.Add(time.Hour))},
+			b:    &RpkCloudAuth{AuthToken: sign(t, "other@example.com", now.Add(time.Hour))},
+		},
+		{
+			name: "different opaque tokens",
+			a:    &RpkCloudAuth{AuthToken: "opaque"},
+			b:    &RpkCloudAuth{AuthToken: "other"},
+		},
+		{
+			name: "different cloud",
+			a:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret"},
+			b:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret", CloudURL: "https://cloud.example.com"},
+		},
+		{
+			name: "no credentials",
+			a:    &RpkCloudAuth{Name: "a"},
+			b:    &RpkCloudAuth{Name: "a"},
+		},
+		{
+			name: "nil",
+			a:    &RpkCloudAuth{ClientID: "id", ClientSecret: "secret"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.exp, test.a.SameCredentials(test.b))
+			require.Equal(t, test.exp, test.b.SameCredentials(test.a))
+		})
+	}
+}
+
+func TestRpkCloudAuthExpired(t *testing.T) {
+	sign := func(t *testing.T, exp time.Time) string {
+		tok := jwt.New()
+		if !exp.IsZero() {
+			tok.Set(jwt.ExpirationKey, exp)
+		}
+		signed, err := jwt.Sign(tok, jwa.HS256, []byte("secret"))
+		require.NoError(t, err)
+		return string(signed)
+	}
+
+	for _, test := range []struct {
+		name    string
+		token   func(*testing.T) string
+		expired bool
+		unknown bool
+	}{
+		{
+			name:  "valid",
+			token: func(t *testing.T) string { return sign(t, time.Now().Add(time.Hour)) },
+		},
+		{
+			name:    "expired",
+			token:   func(t *testing.T) string { return sign(t, time.Now().Add(-time.Minute)) },
+			expired: true,
+		},
+		{
+			name:    "no exp",
+			token:   func(t *testing.T) string { return sign(t, time.Time{}) },
+			unknown: true,
+		},
+		{
+			name:    "malformed",
+			token:   func(*testing.T) string { return "not.a.jwt" },
+			unknown: true,
+		},
+		{
+			name:    "empty",
+			token:   func(*testing.T) string { return "" },
+			unknown: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := RpkCloudAuth{AuthToken: test.token(t)}
+			expired, err := a.Expired()
+			if test.unknown {
+				require.True(t, errors.Is(err, ErrUnknownTokenExpiry), "got err %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expired, expired)
+		})
+	}
+}
+
+func TestRpkCloudAuthRefresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("grant_type") != "client_credentials" ||
+			r.Form.Get("client_id") != "id" ||
+			r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	a := RpkCloudAuth{Name: "foo", ClientID: "id", ClientSecret: "secret", AuthToken: "old"}
+	require.NoError(t, a.Refresh(context.Background(), ts.URL, ts.Client()))
+	require.Equal(t, "new-access", a.AuthToken)
+	require.Equal(t, "new-refresh", a.RefreshToken)
+
+	bad := RpkCloudAuth{Name: "bar", ClientID: "id", ClientSecret: "wrong", AuthToken: "old"}
+	require.Error(t, bad.Refresh(context.Background(), ts.URL, ts.Client()))
+	require.Equal(t, "old", bad.AuthToken)
+
+	none := RpkCloudAuth{Name: "biz"}
+	require.Error(t, none.Refresh(context.Background(), ts.URL, ts.Client()))
+
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/secrets/client": testfs.RFile("secret\n"),
+	})
+	ref := RpkCloudAuth{Name: "ref", ClientID: "id", ClientSecret: "file:/secrets/client"}
+	require.NoError(t, ref.RefreshWithFs(context.Background(), fs, ts.URL, ts.Client()))
+	require.Equal(t, "new-access", ref.AuthToken)
+	require.Equal(t, "file:/secrets/client", ref.ClientSecret)
+}
+
+func TestRpkYamlResolveParents(t *testing.T) {
+	sasl := &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"}
+	base := RpkProfile{
+		Name:        "base",
+		Description: "shared settings",
+		Labels:      map[string]string{"env": "prod", "team": "a"},
+		Annotations: map[string]string{"provisioned-by": "terraform"},
+		KafkaAPI: RpkKafkaAPI{
+			Brokers: []string{"base:9092"},
+			TLS:     &TLS{TruststoreFile: "/ca.pem", CertFile: "/cert.pem"},
+			SASL:    sasl,
+		},
+		AdminAPI: RpkAdminAPI{Addresses: []string{"base:9644"}},
+	}
+
+	for _, test := range []struct {
+		name     string
+		profiles []RpkProfile
+		expErr   bool
+		exp      map[string]RpkProfile
+	}{
+		{
+			name: "single level override",
+			profiles: []RpkProfile{
+				{
+					Name:   "child",
+					Parent: "base",
+					Labels: map[string]string{"team": "b"},
+					KafkaAPI: RpkKafkaAPI{
+						Brokers: []string{"child:9092"},
+						TLS:     &TLS{CertFile: "/child.pem"},
+					},
+				},
+				base,
+			},
+			exp: map[string]RpkProfile{
+				"child": {
+					Name:   "child",
+					Parent: "base",
+					Labels: map[string]string{"env": "prod", "team": "b"},
+					KafkaAPI: RpkKafkaAPI{
+						Brokers: []string{"child:9092"},
+						TLS:     &TLS{TruststoreFile: "/ca.pem", CertFile: "/child.pem"},
+						SASL:    sasl,
+					},
+					AdminAPI: RpkAdminAPI{Addresses: []string{"base:9644"}},
+				},
+				"base": base,
+			},
+		},
+		{
+			name: "multi level chain",
+			profiles: []RpkProfile{
+				{Name: "a", Parent: "b", KafkaAPI: RpkKafkaAPI{Brokers: []string{"a:9092"}}},
+				{Name: "b", Parent: "base", AdminAPI: RpkAdminAPI{Addresses: []string{"b:9644"}}},
+				base,
+			},
+			exp: map[string]RpkProfile{
+				"a": {
+					Name:   "a",
+					Parent: "b",
+					Labels: base.Labels,
+					KafkaAPI: RpkKafkaAPI{
+						Brokers: []string{"a:9092"},
+						TLS:     base.KafkaAPI.TLS,
+						SASL:    sasl,
+					},
+					AdminAPI: RpkAdminAPI{Addresses: []string{"b:9644"}},
+				},
+				"b": {
+					Name:     "b",
+					Parent:   "base",
+					Labels:   base.Labels,
+					KafkaAPI: base.KafkaAPI,
+					AdminAPI: RpkAdminAPI{Addresses: []string{"b:9644"}},
+				},
+			},
+		},
+		{
+			name: "cycle",
+			profiles: []RpkProfile{
+				{Name: "a", Parent: "b"},
+				{Name: "b", Parent: "c"},
+				{Name: "c", Parent: "a"},
+			},
+			expErr: true,
+		},
+		{
+			name:     "self parent",
+			profiles: []RpkProfile{{Name: "a", Parent: "a"}},
+			expErr:   true,
+		},
+		{
+			name:     "missing parent",
+			profiles: []RpkProfile{{Name: "a", Parent: "nope"}},
+			expErr:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			y := RpkYaml{Profiles: test.profiles}
+			y = y.Clone()
+			err := y.resolveParents()
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for name, exp := range test.exp {
+				require.Equal(t, exp, *y.Profile(name), "profile %q", name)
+			}
+		})
+	}
+}
+
+func TestRpkYamlExportProfile(t *testing.T) {
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "local",
+		Profiles: []RpkProfile{
+			{
+				Name: "local",
+				KafkaAPI: RpkKafkaAPI{
+					Brokers: []string{"127.0.0.1:9092"},
+					SASL:    &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"},
+				},
+			},
+			{
+				Name:      "cloud",
+				Parent:    "local",
+				FromCloud: true,
+				CloudCluster: RpkCloudCluster{
+					ClusterID: "abc",
+					AuthOrgID: "org",
+					AuthKind:  CloudAuthClientCredentials,
+				},
+			},
+			{
+				Name:         "dangling",
+				FromCloud:    true,
+				CloudCluster: RpkCloudCluster{AuthOrgID: "nope", AuthKind: CloudAuthSSO},
+			},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "other", OrgID: "other", Kind: CloudAuthSSO, AuthToken: "other-token"},
+			{Name: "auth", OrgID: "org", Kind: CloudAuthClientCredentials, AuthToken: "token", ClientID: "id", ClientSecret: "secret"},
+		},
+	}
+	orig := y.Clone()
+
+	export, err := y.ExportProfile("cloud", true, false)
+	require.NoError(t, err)
+	require.Equal(t, "cloud", export.CurrentProfile)
+	require.Len(t, export.Profiles, 1)
+	p := export.Profiles[0]
+	require.Equal(t, "cloud", p.Name)
+	require.Empty(t, p.Parent, "export must not refer to a parent that is not exported")
+	require.Equal(t, []string{"127.0.0.1:9092"}, p.KafkaAPI.Brokers)
+	require.Equal(t, "pass", p.KafkaAPI.SASL.Password)
+	require.Equal(t, []RpkCloudAuth{y.CloudAuths[1]}, export.CloudAuths)
+	require.Equal(t, "org", export.CurrentCloudAuthOrgID)
+	require.Equal(t, CloudAuthClientCredentials, export.CurrentCloudAuthKind)
+
+	export, err = y.ExportProfile("cloud", false, false)
+	require.NoError(t, err)
+	require.Empty(t, export.CloudAuths)
+	require.Empty(t, export.CurrentCloudAuthOrgID)
+
+	export, err = y.ExportProfile("cloud", true, true)
+	require.NoError(t, err)
+	require.Empty(t, export.Profiles[0].KafkaAPI.SASL.Password)
+	require.Equal(t, "user", export.Profiles[0].KafkaAPI.SASL.User)
+	a := export.CloudAuths[0]
+	require.Empty(t, a.AuthToken)
+	require.Empty(t, a.ClientSecret)
+	require.Equal(t, "id", a.ClientID)
+
+	// Exporting never modifies the receiver.
+	require.Equal(t, orig, y)
+
+	_, err = y.ExportProfile("dangling", true, false)
+	require.True(t, errors.Is(err, ErrAuthNotFound), "got err %v", err)
+	_, err = y.ExportProfile("missing", false, false)
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+}
+
+func TestRpkYamlClone(t *testing.T) {
+	y := RpkYaml{
+		fileLocation:   "/rpk.yaml",
+		fileRaw:        []byte("version: 1\n"),
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:         "foo",
+			Labels:       map[string]string{"env": "prod"},
+			FromCloud:    true,
+			CloudCluster: RpkCloudCluster{ClusterID: "id", AuthOrgID: "org"},
+			KafkaAPI: RpkKafkaAPI{
+				Brokers: []string{"127.0.0.1:9092"},
+				TLS:     &TLS{CertFile: "cert.pem"},
+				SASL:    &SASL{User: "user"},
+			},
+		}},
+		CloudAuths: []RpkCloudAuth{{Name: "auth", OrgID: "org"}},
+	}
+	orig := y.Clone()
+	require.Equal(t, y, orig)
+
+	c := y.Clone()
+	require.Equal(t, "/rpk.yaml", c.FileLocation())
+	p := &c.Profiles[0]
+	p.CloudCluster.ClusterID = "changed"
+	p.CloudCluster.AuthOrgID = "changed"
+	p.Labels["env"] = "dev"
+	p.KafkaAPI.Brokers[0] = "changed"
+	p.KafkaAPI.TLS.CertFile = "changed"
+	p.KafkaAPI.SASL.User = "changed"
+	c.CloudAuths[0].Name = "changed"
+	c.fileRaw[0] = 'x'
+	c.Profiles = append(c.Profiles, RpkProfile{Name: "bar"})
+
+	require.Equal(t, orig, y, "original was modified through its clone")
+}
+
+func TestRpkYamlRedacted(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:     "foo",
+			KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+		}},
+		CloudAuths: []RpkCloudAuth{
+			{
+				Name:         "sso",
+				OrgID:        "org",
+				Kind:         CloudAuthSSO,
+				AuthToken:    "token",
+				RefreshToken: "refresh",
+			},
+			{
+				Name:         "creds",
+				OrgID:        "org",
+				Kind:         CloudAuthClientCredentials,
+				ClientID:     "id",
+				ClientSecret: "secret",
+			},
+		},
+	}
+	orig := y.Clone()
+
+	r := y.Redacted()
+	require.Equal(t, orig, y, "receiver was modified")
+
+	exp := orig.Clone()
+	exp.CloudAuths[0].AuthToken = "(REDACTED)"
+	exp.CloudAuths[0].RefreshToken = "(REDACTED)"
+	exp.CloudAuths[1].ClientSecret = "(REDACTED)"
+	require.Equal(t, exp, r)
+
+	r.Profiles[0].KafkaAPI.Brokers[0] = "changed"
+	require.Equal(t, "127.0.0.1:9092", y.Profiles[0].KafkaAPI.Brokers[0])
+}
+
+func TestRpkYamlDedupeProfiles(t *testing.T) {
+	mk := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile: "foo",
+			Profiles: []RpkProfile{
+				{Name: "foo", Description: "foo 1"},
+				{Name: "bar", Description: "bar 1"},
+				{Name: "foo", Description: "foo 2"},
+				{Name: "baz"},
+				{Name: "foo", Description: "foo 3"},
+				{Name: "bar", Description: "bar 2"},
+			},
+		}
+	}
+	descriptions := func(y RpkYaml) []string {
+		var ds []string
+		for _, p := range y.Profiles {
+			ds = append(ds, p.Name+": "+p.Description)
+		}
+		return ds
+	}
+
+	t.Run("keep first", func(t *testing.T) {
+		y := mk()
+		require.Equal(t, []string{"foo", "foo", "bar"}, y.DedupeProfiles(true))
+		require.Equal(t, []string{"foo: foo 1", "bar: bar 1", "baz: "}, descriptions(y))
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.NoError(t, y.Validate())
+	})
+
+	t.Run("keep last", func(t *testing.T) {
+		y := mk()
+		require.Equal(t, []string{"foo", "bar", "foo"}, y.DedupeProfiles(false))
+		require.Equal(t, []string{"baz: ", "foo: foo 3", "bar: bar 2"}, descriptions(y))
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.NoError(t, y.Validate())
+	})
+
+	t.Run("no duplicates", func(t *testing.T) {
+		y := RpkYaml{Profiles: []RpkProfile{{Name: "foo"}, {Name: "bar"}}}
+		for _, keepFirst := range []bool{true, false} {
+			require.Empty(t, y.DedupeProfiles(keepFirst))
+			require.Equal(t, []string{"foo", "bar"}, []string{y.Profiles[0].Name, y.Profiles[1].Name})
+		}
+		require.Empty(t, (&RpkYaml{}).DedupeProfiles(true))
+	})
+}
+
+func TestRpkYamlCopyProfile(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{
+			{
+				Name:         "foo",
+				CloudCluster: RpkCloudCluster{ClusterID: "id", ClusterName: "name"},
+				KafkaAPI: RpkKafkaAPI{
+					Brokers: []string{"127.0.0.1:9092"},
+					TLS:     &TLS{TruststoreFile: "ca.pem"},
+					SASL:    &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-256"},
+				},
+				AdminAPI: RpkAdminAPI{Addresses: []string{"127.0.0.1:9644"}},
+			},
+			{Name: "bar"},
+		},
+	}
+
+	_, err := y.CopyProfile("missing", "biz")
+	require.True(t, errors.Is(err, ErrProfileNotFound), "got err %v", err)
+	_, err = y.CopyProfile("foo", "bar")
+	require.True(t, errors.Is(err, ErrDuplicateProfile), "got err %v", err)
+
+	cp, err := y.CopyProfile("foo", "biz")
+	require.NoError(t, err)
+	require.Equal(t, "biz", cp.Name)
+	require.Equal(t, "biz", y.CurrentProfile)
+	require.Same(t, y.Profile("biz"), cp)
+
+	src := y.Profile("foo")
+	require.Equal(t, src.CloudCluster, cp.CloudCluster)
+	require.Equal(t, src.KafkaAPI, cp.KafkaAPI)
+
+	cp.CloudCluster.ClusterID = "changed"
+	cp.KafkaAPI.Brokers[0] = "changed"
+	cp.KafkaAPI.TLS.TruststoreFile = "changed"
+	cp.KafkaAPI.SASL.User = "changed"
+	cp.AdminAPI.Addresses[0] = "changed"
+	require.Equal(t, "id", src.CloudCluster.ClusterID)
+	require.Equal(t, "127.0.0.1:9092", src.KafkaAPI.Brokers[0])
+	require.Equal(t, "ca.pem", src.KafkaAPI.TLS.TruststoreFile)
+	require.Equal(t, "user", src.KafkaAPI.SASL.User)
+	require.Equal(t, "127.0.0.1:9644", src.AdminAPI.Addresses[0])
+}
+
+func TestLoadRpkYamlOrDefault(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/valid.yaml": testfs.RFile(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+`, currentRpkYAMLVersion)),
+		"/corrupt.yaml": testfs.RFile("version: [\n"),
+		"/newer.yaml":   testfs.RFile("version: 1000\n"),
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		y, exists, err := LoadRpkYamlOrDefault(fs, "/missing.yaml")
+		require.NoError(t, err)
+		require.False(t, exists)
+		require.Equal(t, "/missing.yaml", y.FileLocation())
+		require.Equal(t, DefaultProfileName, y.CurrentProfile)
+		require.NotNil(t, y.Profile(y.CurrentProfile))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		y, exists, err := LoadRpkYamlOrDefault(fs, "/valid.yaml")
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, "/valid.yaml", y.FileLocation())
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.Len(t, y.Profiles, 1)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		_, exists, err := LoadRpkYamlOrDefault(fs, "/corrupt.yaml")
+		require.Error(t, err)
+		require.True(t, exists)
+	})
+
+	t.Run("newer", func(t *testing.T) {
+		_, exists, err := LoadRpkYamlOrDefault(fs, "/newer.yaml")
+		require.Error(t, err)
+		require.True(t, exists)
+	})
+}
+
+func TestLoadRpkYamlFromReader(t *testing.T) {
+	t.Setenv(EnvRpkYamlPath, "/default/rpk.yaml")
+
+	y, err := LoadRpkYamlFromReader(strings.NewReader(fmt.Sprintf(`version: %d
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [127.0.0.1:9092]
+`, currentRpkYAMLVersion)))
+	require.NoError(t, err)
+	require.Equal(t, "foo", y.CurrentProfile)
+	require.Equal(t, []string{"127.0.0.1:9092"}, y.Profile("foo").KafkaAPI.Brokers)
+	require.Empty(t, y.FileLocation())
+
+	// Write goes to the default path: nothing was loaded from it, so the
+	// unchanged configuration is still written.
+	fs := afero.NewMemMapFs()
+	require.NoError(t, y.Write(fs))
+	written, _, err := LoadRpkYamlOrDefault(fs, "/default/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:9092"}, written.Profile("foo").KafkaAPI.Brokers)
+
+	// Unversioned files are migrated like files on disk.
+	y, err = LoadRpkYamlFromReader(strings.NewReader("profiles:\n    - kafka_api: {}\n"))
+	require.NoError(t, err)
+	require.Equal(t, DefaultProfileName, y.CurrentProfile)
+
+	for _, bad := range []string{"version: [\n", "version: 1000\n", "redpanda: {}\n"} {
+		_, err := LoadRpkYamlFromReader(strings.NewReader(bad))
+		require.Error(t, err, "input %q", bad)
+	}
+}
+
+func TestRpkYamlReload(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	write := func(current string) {
+		require.NoError(t, afero.WriteFile(fs, "/rpk.yaml", []byte(fmt.Sprintf(`version: %d
+current_profile: %s
+profiles:
+    - name: foo
+    - name: bar
+`, currentRpkYAMLVersion, current)), 0o644))
+	}
+	write("foo")
+	y, _, err := LoadRpkYamlOrDefault(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "foo", y.CurrentProfile)
+
+	write("bar")
+	require.NoError(t, y.Reload(fs))
+	require.Equal(t, "bar", y.CurrentProfile)
+	require.Equal(t, "/rpk.yaml", y.FileLocation())
+
+	// An invalid file leaves y untouched.
+	require.NoError(t, afero.WriteFile(fs, "/rpk.yaml", []byte("version: [\n"), 0o644))
+	require.Error(t, y.Reload(fs))
+	require.Equal(t, "bar", y.CurrentProfile)
+
+	require.NoError(t, fs.Remove("/rpk.yaml"))
+	err = y.Reload(fs)
+	require.True(t, errors.Is(err, os.ErrNotExist), "got err %v", err)
+	require.ErrorContains(t, err, "no longer exists")
+	require.Equal(t, "bar", y.CurrentProfile)
+
+	y, err = LoadRpkYamlFromReader(strings.NewReader(fmt.Sprintf("version: %d\n", currentRpkYAMLVersion)))
+	require.NoError(t, err)
+	require.ErrorContains(t, y.Reload(fs), "not loaded from a file")
+}
+
+func TestLoadRpkYamlFromURL(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rpk.yaml":
+			fmt.Fprintf(w, "version: %d\ncurrent_profile: foo\nprofiles:\n    - name: foo\n", currentRpkYAMLVersion)
+		case "/slow.yaml":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	y, err := LoadRpkYamlFromURL(context.Background(), srv.Client(), srv.URL+"/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "foo", y.CurrentProfile)
+	require.NotNil(t, y.Profile("foo"))
+	require.Empty(t, y.FileLocation())
+
+	_, err = LoadRpkYamlFromURL(context.Background(), srv.Client(), srv.URL+"/missing.yaml?signature=hush")
+	require.ErrorContains(t, err, "404 Not Found")
+	require.NotContains(t, err.Error(), "hush")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = LoadRpkYamlFromURL(ctx, srv.Client(), srv.URL+"/slow.yaml")
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "got err %v", err)
+
+	// Dial failures do not leak the query either.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+	_, err = LoadRpkYamlFromURL(context.Background(), nil, closedURL+"/rpk.yaml?signature=hush")
+	require.ErrorContains(t, err, "unable to fetch "+closedURL+"/rpk.yaml")
+	require.NotContains(t, err.Error(), "hush")
+}
+
+func TestRpkYamlPreservesUnknownFields(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/rpk.yaml": testfs.RFile(fmt.Sprintf(`version: %d
+current_profile: foo
+future_top: 1
+profiles:
+    - name: foo
+      future_profile:
+        nested: [a, b]
+    - name: bar
+cloud_auth:
+    - name: biz
+      org_id: biz-id
+      future_auth: true
+`, currentRpkYAMLVersion)),
+	})
+
+	y, exists, err := LoadRpkYamlOrDefault(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.True(t, exists)
+	_, err = y.SetCurrentProfile("bar")
+	require.NoError(t, err)
+	require.NoError(t, y.Write(fs))
+
+	raw, err := afero.ReadFile(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.Equal(t, "bar", got["current_profile"])
+	require.Equal(t, 1, got["future_top"])
+	profiles := got["profiles"].([]any)
+	require.Equal(t, map[string]any{"nested": []any{"a", "b"}}, profiles[0].(map[string]any)["future_profile"])
+	auths := got["cloud_auth"].([]any)
+	require.Equal(t, true, auths[0].(map[string]any)["future_auth"])
+
+	// Unknown fields are inlined in JSON too, rather than under "Extra".
+	raw, err = json.Marshal(y)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "Extra")
+	got = nil
+	require.NoError(t, json.Unmarshal(raw, &got))
+	require.Equal(t, "bar", got["current_profile"])
+	require.Equal(t, float64(1), got["future_top"])
+	profiles = got["profiles"].([]any)
+	require.Equal(t, "foo", profiles[0].(map[string]any)["name"])
+	require.Equal(t, map[string]any{"nested": []any{"a", "b"}}, profiles[0].(map[string]any)["future_profile"])
+	auths = got["cloud_auth"].([]any)
+	require.Equal(t, true, auths[0].(map[string]any)["future_auth"])
+}
+
+func TestDefaultVirtualRpkYamlNames(t *testing.T) {
+	y, err := defaultVirtualRpkYaml()
+	require.NoError(t, err)
+	require.Equal(t, DefaultProfileName, y.CurrentProfile)
+	require.Equal(t, []string{DefaultProfileName}, y.ProfileNames())
+	require.Equal(t, []string{DefaultAuthName}, y.AuthNames())
+	require.Equal(t, DefaultAuthName, y.CurrentAuth().Name)
+	require.Equal(t, DefaultProfileName, DefaultRpkProfile().Name)
+	require.Equal(t, DefaultAuthName, DefaultRpkCloudAuth().Name)
+}
+
+func TestRpkYamlWriteDefault(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	y, err := defaultVirtualRpkYaml()
+	require.NoError(t, err)
+	y.fileLocation = "/rpk.yaml"
+
+	require.NoError(t, y.Write(fs))
+	exists, err := afero.Exists(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.False(t, exists, "default rpk.yaml was written")
+
+	y.Profiles[0].KafkaAPI.Brokers = []string{"127.0.0.1:9092"}
+	require.NoError(t, y.Write(fs))
+	exists, err = afero.Exists(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.True(t, exists, "modified rpk.yaml was not written")
+}
+
+func TestRpkYamlWriteFileMode(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	mode := func(t *testing.T, fs afero.Fs, path string) os.FileMode {
+		stat, err := fs.Stat(path)
+		require.NoError(t, err)
+		return stat.Mode().Perm()
+	}
+	plain := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles:       []RpkProfile{{Name: "foo", KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}}}},
+	}
+	withToken := plain.Clone()
+	withToken.CloudAuths = []RpkCloudAuth{{Name: "auth", Organization: "org", OrgID: "org-id", Kind: CloudAuthSSO, AuthToken: "token"}}
+
+	t.Run("no secrets", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, plain.WriteAt(fs, path))
+		require.Equal(t, os.FileMode(0o644), mode(t, fs, path))
+	})
+
+	t.Run("token", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, withToken.WriteAt(fs, path))
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path))
+	})
+
+	t.Run("existing file is restricted", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, plain.WriteAt(fs, path))
+		require.Equal(t, os.FileMode(0o644), mode(t, fs, path))
+
+		require.NoError(t, withToken.WriteAt(fs, path))
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path))
+
+		// Backups hold the prior secrets and are restricted too.
+		withToken.fileLocation = path
+		withToken.CloudAuths[0].AuthToken = "new-token"
+		require.NoError(t, withToken.WriteWithBackup(fs))
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path))
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path+".bak"))
+
+		// Removing every secret relaxes the new file, but the
+		// backup still holds the old tokens.
+		noToken := withToken.Clone()
+		noToken.CloudAuths[0].AuthToken = ""
+		require.NoError(t, noToken.WriteWithBackup(fs))
+		bak, err := afero.ReadFile(fs, path+".bak")
+		require.NoError(t, err)
+		require.Contains(t, string(bak), "new-token")
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path+".bak"))
+	})
+
+	t.Run("backup of a plain file", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		y := plain.Clone()
+		y.fileLocation = path
+		require.NoError(t, y.WriteAt(fs, path))
+		y.Profiles = append(y.Profiles, RpkProfile{Name: "other"})
+		require.NoError(t, y.WriteWithBackup(fs))
+		require.Equal(t, os.FileMode(0o644), mode(t, fs, path))
+		require.Equal(t, os.FileMode(0o600), mode(t, fs, path+".bak"))
+	})
+}
+
+func TestRpkYamlWriteIfChanged(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	fs := afero.NewMemMapFs()
+	initial := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:     "foo",
+			KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+		}},
+	}
+	require.NoError(t, initial.WriteAt(fs, path))
+	before, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+
+	load := func() *RpkYaml {
+		cfg, err := (&Params{ConfigFlag: path}).Load(fs)
+		require.NoError(t, err)
+		y, ok := cfg.ActualRpkYaml()
+		require.True(t, ok)
+		return y
+	}
+
+	wrote, err := load().WriteIfChanged(fs)
+	require.NoError(t, err)
+	require.False(t, wrote)
+	after, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	y := load()
+	y.Profile("foo").KafkaAPI.Brokers = []string{"127.0.0.1:9093"}
+	wrote, err = y.WriteIfChanged(fs)
+	require.NoError(t, err)
+	require.True(t, wrote)
+	require.Equal(t, []string{"127.0.0.1:9093"}, load().Profile("foo").KafkaAPI.Brokers)
+}
+
+func TestRpkYamlWriteSorted(t *testing.T) {
+	mk := func(profiles []string, auths []string) RpkYaml {
+		y := RpkYaml{Version: currentRpkYAMLVersion, CurrentProfile: "b"}
+		for _, name := range profiles {
+			y.Profiles = append(y.Profiles, RpkProfile{Name: name})
+		}
+		for _, name := range auths {
+			y.CloudAuths = append(y.CloudAuths, RpkCloudAuth{Name: name, OrgID: name})
+		}
+		y.fileLocation = "/rpk.yaml"
+		return y
+	}
+	write := func(y RpkYaml) string {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, y.WriteSorted(fs))
+		raw, err := afero.ReadFile(fs, "/rpk.yaml")
+		require.NoError(t, err)
+		return string(raw)
+	}
+
+	l := mk([]string{"c", "a", "B"}, []string{"z", "y"})
+	r := mk([]string{"B", "c", "a"}, []string{"y", "z"})
+	sorted := write(l)
+	require.Equal(t, sorted, write(r))
+	require.Less(t, strings.Index(sorted, "name: a"), strings.Index(sorted, "name: B"))
+	require.Less(t, strings.Index(sorted, "name: B"), strings.Index(sorted, "name: c"))
+	require.Less(t, strings.Index(sorted, "name: y"), strings.Index(sorted, "name: z"))
+
+	// The in memory order is unchanged.
+	require.Equal(t, "c", l.Profiles[0].Name)
+	require.Equal(t, "z", l.CloudAuths[0].Name)
+}
+
+func TestRpkYamlWriteWithBackup(t *testing.T) {
+	prior := `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        brokers: [127.0.0.1:9092]
+`
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: prior},
+	})
+	cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	y, ok := cfg.ActualRpkYaml()
+	require.True(t, ok)
+
+	y.Profile("foo").KafkaAPI.Brokers = []string{"127.0.0.1:9093"}
+	require.NoError(t, y.WriteWithBackup(fs))
+
+	bak, err := afero.ReadFile(fs, "/etc/rpk/rpk.yaml.bak")
+	require.NoError(t, err)
+	require.Equal(t, prior, string(bak))
+
+	cfg, err = (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+	require.NoError(t, err)
+	y, _ = cfg.ActualRpkYaml()
+	require.Equal(t, []string{"127.0.0.1:9093"}, y.Profile("foo").KafkaAPI.Brokers)
+
+	// Without a prior file, there is nothing to back up.
+	fs = afero.NewMemMapFs()
+	y.fileLocation = "/rpk.yaml"
+	y.fileRaw = nil
+	require.NoError(t, y.WriteWithBackup(fs))
+	exists, err := afero.Exists(fs, "/rpk.yaml.bak")
+	require.NoError(t, err)
+	require.False(t, exists, "backup written without a prior file")
+	exists, err = afero.Exists(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.True(t, exists, "rpk.yaml was not written")
+}
+
+func TestRpkYamlWriteTo(t *testing.T) {
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:     "foo",
+			KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+		}},
+		CloudAuths: []RpkCloudAuth{{Name: "bar", AuthToken: "secret"}},
+	}
+
+	var buf bytes.Buffer
+	redacted := y.Redacted()
+	n, err := redacted.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+
+	var got RpkYaml
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, "foo", got.CurrentProfile)
+	require.Equal(t, []string{"127.0.0.1:9092"}, got.Profile("foo").KafkaAPI.Brokers)
+	require.Equal(t, "(REDACTED)", got.CloudAuths[0].AuthToken)
+}
+
+func TestRpkYamlWriteAtStdout(t *testing.T) {
+	var buf bytes.Buffer
+	old := stdout
+	stdout = &buf
+	defer func() { stdout = old }()
+
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles:       []RpkProfile{{Name: "foo"}},
+	}
+	fs := afero.NewMemMapFs()
+	require.NoError(t, y.WriteAt(fs, "-"))
+
+	exp, err := yaml.Marshal(&y)
+	require.NoError(t, err)
+	require.Equal(t, string(exp), buf.String())
+
+	// Nothing is written to the filesystem, not even a lock file.
+	for _, path := range []string{"-", "-.lock"} {
+		exists, err := afero.Exists(fs, path)
+		require.NoError(t, err)
+		require.False(t, exists, "path %q", path)
+	}
+}
+
+func TestRpkYamlWouldChange(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	y := RpkYaml{
+		fileLocation:   "/rpk.yaml",
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles:       []RpkProfile{{Name: "foo"}},
+	}
+
+	// Missing file.
+	changed, b, err := y.WouldChange(fs)
+	require.NoError(t, err)
+	require.True(t, changed)
+	exists, err := afero.Exists(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Unchanged.
+	require.NoError(t, afero.WriteFile(fs, "/rpk.yaml", b, 0o644))
+	changed, b2, err := y.WouldChange(fs)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, b, b2)
+
+	// Changed.
+	y.Profiles[0].Description = "changed"
+	changed, b3, err := y.WouldChange(fs)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Contains(t, string(b3), "changed")
+	raw, err := afero.ReadFile(fs, "/rpk.yaml")
+	require.NoError(t, err)
+	require.Equal(t, b, raw)
+}
+
+func TestRpkYamlConcurrentWrite(t *testing.T) {
+	fs := afero.NewOsFs()
+	path := filepath.Join(t.TempDir(), "rpk.yaml")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			y := RpkYaml{Version: 5}
+			for j := 0; j < 50; j++ {
+				y.PushProfile(RpkProfile{
+					Name:     fmt.Sprintf("writer-%d-%d", i, j),
+					KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+				})
+			}
+			for j := 0; j < 10; j++ {
+				if err := y.WriteAt(fs, path); err != nil {
+					t.Errorf("unable to write: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	raw, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	var got RpkYaml
+	require.NoError(t, yaml.Unmarshal(raw, &got))
+	require.Len(t, got.Profiles, 50)
+}
+
+func TestRpkYamlProfileAliases(t *testing.T) {
+	const path = "/etc/rpk/rpk.yaml"
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "dev",
+		Profiles: []RpkProfile{
+			{Name: "production-us-east-1", Aliases: []string{"p", "prod"}},
+			{Name: "dev"},
+			// A profile name takes precedence over another
+			// profile's alias.
+			{Name: "staging", Aliases: []string{"dev"}},
+		},
+	}
+	require.Equal(t, "production-us-east-1", y.Profile("p").Name)
+	require.Equal(t, "production-us-east-1", y.Profile("prod").Name)
+	require.Equal(t, "dev", y.Profile("dev").Name)
+	require.True(t, y.HasProfile("p"))
+	require.Nil(t, y.Profile("q"))
+
+	p, err := y.SetCurrentProfile("p")
+	require.NoError(t, err)
+	require.Equal(t, "production-us-east-1", p.Name)
+	require.Equal(t, "production-us-east-1", y.CurrentProfile)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, y.WriteAt(fs, path))
+	loaded, _, err := LoadRpkYamlOrDefault(fs, path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"p", "prod"}, loaded.Profile("production-us-east-1").Aliases)
+	require.Equal(t, "production-us-east-1", loaded.Profile("prod").Name)
+
+	require.NoError(t, loaded.RenameProfile("p", "prod-east"))
+	require.Equal(t, "prod-east", loaded.CurrentProfile)
+	require.NoError(t, loaded.DeleteProfile("prod"))
+	require.Nil(t, loaded.Profile("prod-east"))
+	require.Equal(t, []string{"dev", "staging"}, loaded.ProfileNames())
+}
+
+func TestRpkYamlValidate(t *testing.T) {
+	valid := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile:        "foo",
+			CurrentCloudAuthOrgID: "org",
+			CurrentCloudAuthKind:  CloudAuthSSO,
+			Profiles: []RpkProfile{
+				{Name: "foo", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org", AuthKind: CloudAuthSSO}},
+				{Name: "bar"},
+			},
+			CloudAuths: []RpkCloudAuth{
+				{Name: "sso", OrgID: "org", Kind: CloudAuthSSO},
+			},
+		}
+	}
+	for _, test := range []struct {
+		name   string
+		mutate func(*RpkYaml)
+		expErr []string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "valid empty",
+			mutate: func(y *RpkYaml) {
+				*y = RpkYaml{}
+			},
+		},
+		{
+			name:   "missing current profile",
+			mutate: func(y *RpkYaml) { y.CurrentProfile = "missing" },
+			expErr: []string{`current profile "missing" does not exist`},
+		},
+		{
+			name:   "missing current auth",
+			mutate: func(y *RpkYaml) { y.CurrentCloudAuthKind = CloudAuthClientCredentials },
+			expErr: []string{`current cloud auth with org ID "org" and kind "client-credentials" does not exist`},
+		},
+		{
+			name: "current auth differs from current profile auth",
+			mutate: func(y *RpkYaml) {
+				y.CloudAuths = append(y.CloudAuths, RpkCloudAuth{Name: "cc", OrgID: "org", Kind: CloudAuthClientCredentials})
+				y.CurrentCloudAuthKind = CloudAuthClientCredentials
+			},
+			expErr: []string{`current profile "foo" uses cloud auth with org ID "org" and kind "sso", but the current cloud auth has org ID "org" and kind "client-credentials"`},
+		},
+		{
+			name: "current auth unset",
+			mutate: func(y *RpkYaml) {
+				y.CurrentCloudAuthOrgID, y.CurrentCloudAuthKind = "", ""
+			},
+			expErr: []string{`current profile "foo" uses cloud auth with org ID "org" and kind "sso", but the current cloud auth has org ID "" and kind ""`},
+		},
+		{
+			name: "non-cloud current profile with any current auth",
+			mutate: func(y *RpkYaml) {
+				y.CloudAuths = append(y.CloudAuths, RpkCloudAuth{Name: "cc", OrgID: "org", Kind: CloudAuthClientCredentials})
+				y.CurrentCloudAuthKind = CloudAuthClientCredentials
+				y.CurrentProfile = "bar"
+			},
+		},
+		{
+			name:   "missing profile auth",
+			mutate: func(y *RpkYaml) { y.Profiles[0].CloudCluster.AuthOrgID = "other" },
+			expErr: []string{`profile "foo" cloud auth with org ID "other" and kind "sso" does not exist`},
+		},
+		{
+			name:   "duplicate profile",
+			mutate: func(y *RpkYaml) { y.Profiles[1].Name = "foo" },
+			expErr: []string{`profile name "foo" is used more than once`},
+		},
+		{
+			name: "valid aliases",
+			mutate: func(y *RpkYaml) {
+				y.Profiles[0].Aliases = []string{"f", "fo"}
+				y.Profiles[1].Aliases = []string{"b"}
+				y.CurrentProfile = "f"
+			},
+		},
+		{
+			name: "alias collision",
+			mutate: func(y *RpkYaml) {
+				y.Profiles[0].Aliases = []string{"x"}
+				y.Profiles[1].Aliases = []string{"x"}
+			},
+			expErr: []string{`alias "x" is used by both profile "foo" and profile "bar"`},
+		},
+		{
+			name:   "alias is a profile name",
+			mutate: func(y *RpkYaml) { y.Profiles[0].Aliases = []string{"bar"} },
+			expErr: []string{`alias "bar" of profile "foo" is the name of a profile`},
+		},
+		{
+			name:   "empty alias",
+			mutate: func(y *RpkYaml) { y.Profiles[1].Aliases = []string{""} },
+			expErr: []string{`profile "bar" has an empty alias`},
+		},
+		{
+			name: "duplicate auth",
+			mutate: func(y *RpkYaml) {
+				y.CloudAuths = append(y.CloudAuths, RpkCloudAuth{Name: "sso", OrgID: "org2", Kind: CloudAuthSSO})
+			},
+			expErr: []string{`cloud auth name "sso" is used more than once`},
+		},
+		{
+			name: "everything",
+			mutate: func(y *RpkYaml) {
+				y.CurrentProfile = "missing"
+				y.Profiles[1].Name = "foo"
+				y.CloudAuths = append(y.CloudAuths, y.CloudAuths[0])
+			},
+			expErr: []string{
+				`current profile "missing" does not exist`,
+				`profile name "foo" is used more than once`,
+				`cloud auth name "sso" is used more than once`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			y := valid()
+			if test.mutate != nil {
+				test.mutate(&y)
+			}
+			err := y.Validate()
+			if len(test.expErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, exp := range test.expErr {
+				require.Contains(t, err.Error(), exp)
+			}
+			require.Len(t, strings.Split(err.Error(), "\n"), len(test.expErr))
+		})
+	}
+}
+
+func TestRpkYamlSyncCurrentCloudAuth(t *testing.T) {
+	y := RpkYaml{
+		CurrentProfile:        "foo",
+		CurrentCloudAuthOrgID: "org",
+		CurrentCloudAuthKind:  CloudAuthClientCredentials,
+		Profiles: []RpkProfile{
+			{Name: "foo", Aliases: []string{"f"}, FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "org", AuthKind: CloudAuthSSO}},
+			{Name: "bar"},
+		},
+		CloudAuths: []RpkCloudAuth{
+			{Name: "cc", OrgID: "org", Kind: CloudAuthClientCredentials},
+			{Name: "sso", OrgID: "org", Kind: CloudAuthSSO},
+		},
+	}
+	require.Error(t, y.Validate())
+
+	// The current profile is selected by alias to ensure aliases resolve.
+	y.CurrentProfile = "f"
+	require.True(t, y.SyncCurrentCloudAuth())
+	require.Equal(t, "org", y.CurrentCloudAuthOrgID)
+	require.Equal(t, CloudAuthSSO, y.CurrentCloudAuthKind)
+	require.NoError(t, y.Validate())
+	require.False(t, y.SyncCurrentCloudAuth(), "already in sync")
+
+	// Profiles without a cloud auth, or whose auth does not exist, leave
+	// the current auth as is.
+	y.Profiles = append(y.Profiles, RpkProfile{Name: "missing-auth", FromCloud: true, CloudCluster: RpkCloudCluster{AuthOrgID: "nope", AuthKind: CloudAuthSSO}})
+	for _, name := range []string{"bar", "missing-auth"} {
+		y.CurrentProfile = name
+		require.False(t, y.SyncCurrentCloudAuth(), "profile %s", name)
+		require.Equal(t, CloudAuthSSO, y.CurrentCloudAuthKind)
+	}
+}
+
+func TestRpkYamlMerge(t *testing.T) {
+	mine := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile:        "foo",
+			CurrentCloudAuthOrgID: "org1",
+			CurrentCloudAuthKind:  CloudAuthSSO,
+			Profiles: []RpkProfile{
+				{Name: "foo", Description: "mine"},
+				{Name: "bar", Description: "mine"},
+			},
+			CloudAuths: []RpkCloudAuth{
+				{Name: "a1", OrgID: "org1", Kind: CloudAuthSSO, AuthToken: "mine"},
+			},
+		}
+	}
+	theirs := RpkYaml{
+		CurrentProfile:        "biz",
+		CurrentCloudAuthOrgID: "org2",
+		CurrentCloudAuthKind:  CloudAuthSSO,
+		Profiles: []RpkProfile{
+			{Name: "bar", Description: "theirs"},
+			{Name: "biz", Description: "theirs", KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}}},
+		},
+		// Auths are matched by org ID and kind, not by name.
+		CloudAuths: []RpkCloudAuth{
+			{Name: "their-a1", OrgID: "org1", Kind: CloudAuthSSO, AuthToken: "theirs"},
+			{Name: "a2", OrgID: "org2", Kind: CloudAuthSSO},
+			{Name: "a1", OrgID: "org1", Kind: CloudAuthClientCredentials},
+		},
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		y := mine()
+		r := y.Merge(theirs, false)
+		require.Equal(t, MergeResult{
+			AddedProfiles:   []string{"biz"},
+			SkippedProfiles: []string{"bar"},
+			AddedAuths:      []string{"a2", "a1"},
+			SkippedAuths:    []string{"their-a1"},
+		}, r)
+		require.Len(t, y.CloudAuths, 3)
+		require.Equal(t, "a1", y.LookupAuth("org1", CloudAuthSSO).Name)
+		require.Equal(t, "mine", y.Profile("bar").Description)
+		require.Equal(t, "theirs", y.Profile("biz").Description)
+		require.Equal(t, "mine", y.LookupAuth("org1", CloudAuthSSO).AuthToken)
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.Equal(t, "org1", y.CurrentCloudAuthOrgID)
+
+		y.Profile("biz").KafkaAPI.Brokers[0] = "changed"
+		require.Equal(t, "127.0.0.1:9092", theirs.Profiles[1].KafkaAPI.Brokers[0])
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		y := mine()
+		r := y.Merge(theirs, true)
+		require.Equal(t, MergeResult{
+			AddedProfiles:       []string{"biz"},
+			OverwrittenProfiles: []string{"bar"},
+			AddedAuths:          []string{"a2", "a1"},
+			OverwrittenAuths:    []string{"their-a1"},
+		}, r)
+		require.Len(t, y.CloudAuths, 3)
+		require.Equal(t, "their-a1", y.LookupAuth("org1", CloudAuthSSO).Name)
+		require.Len(t, y.Profiles, 3)
+		require.Equal(t, "bar", y.Profiles[1].Name)
+		require.Equal(t, "theirs", y.Profiles[1].Description)
+		require.Equal(t, "theirs", y.LookupAuth("org1", CloudAuthSSO).AuthToken)
+		require.Equal(t, "foo", y.CurrentProfile)
+	})
+
+	t.Run("missing current", func(t *testing.T) {
+		y := mine()
+		y.CurrentProfile = ""
+		y.CurrentCloudAuthOrgID = ""
+		y.CurrentCloudAuthKind = ""
+		y.Merge(theirs, false)
+		require.Equal(t, "biz", y.CurrentProfile)
+		require.Equal(t, "org2", y.CurrentCloudAuthOrgID)
+	})
+
+	t.Run("aliases are not names", func(t *testing.T) {
+		y := mine()
+		y.Profiles[0].Aliases = []string{"biz"}
+		y.CurrentProfile = "biz"
+		r := y.Merge(theirs, true)
+		require.Equal(t, []string{"biz"}, r.SkippedProfiles)
+		require.Equal(t, []string{"bar"}, r.OverwrittenProfiles)
+		require.Empty(t, r.AddedProfiles)
+		require.Equal(t, "mine", y.Profile("foo").Description)
+		require.Len(t, y.Profiles, 2)
+		// Their biz was skipped, so it cannot become the current profile.
+		require.Equal(t, "biz", y.CurrentProfile)
+	})
+
+	t.Run("auths are copied", func(t *testing.T) {
+		other := RpkYaml{CloudAuths: []RpkCloudAuth{
+			{Name: "a3", OrgID: "org3", Kind: CloudAuthSSO, Extra: map[string]any{"k": "v"}},
+		}}
+		y := mine()
+		y.Merge(other, false)
+		y.LookupAuth("org3", CloudAuthSSO).Extra["k"] = "changed"
+		require.Equal(t, "v", other.CloudAuths[0].Extra["k"])
+	})
+}
+
+func TestRpkYamlMergeFile(t *testing.T) {
+	fs := testfs.FromMap(map[string]testfs.Fmode{
+		"/shared/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: biz
+current_cloud_auth_org_id: org2
+current_cloud_auth_kind: sso
+profiles:
+    - name: bar
+      description: theirs
+    - name: biz
+      description: theirs
+      kafka_api:
+        tls:
+          ca_file: certs/ca.pem
+cloud_auth:
+    - name: a2
+      org_id: org2
+      kind: sso
+`},
+		"/newer.yaml": {Mode: 0o644, Contents: "version: 999\n"},
+	})
+	mine := func() RpkYaml {
+		return RpkYaml{
+			CurrentProfile:        "foo",
+			CurrentCloudAuthOrgID: "org1",
+			CurrentCloudAuthKind:  CloudAuthSSO,
+			Profiles: []RpkProfile{
+				{Name: "foo", Description: "mine"},
+				{Name: "bar", Description: "mine"},
+			},
+			CloudAuths: []RpkCloudAuth{
+				{Name: "a1", OrgID: "org1", Kind: CloudAuthSSO},
+			},
+		}
+	}
+	exp := MergeResult{
+		AddedProfiles:   []string{"biz"},
+		SkippedProfiles: []string{"bar"},
+		AddedAuths:      []string{"a2"},
+	}
+
+	t.Run("keep current", func(t *testing.T) {
+		y := mine()
+		r, err := y.MergeFile(fs, "/shared/rpk.yaml", false)
+		require.NoError(t, err)
+		require.Equal(t, exp, r)
+		require.Equal(t, "mine", y.Profile("bar").Description)
+		require.Equal(t, "/shared/certs/ca.pem", y.Profile("biz").KafkaAPI.TLS.TruststoreFile)
+		require.Equal(t, "foo", y.CurrentProfile)
+		require.Equal(t, "org1", y.CurrentCloudAuthOrgID)
+	})
+
+	t.Run("adopt current", func(t *testing.T) {
+		y := mine()
+		r, err := y.MergeFile(fs, "/shared/rpk.yaml", true)
+		require.NoError(t, err)
+		require.Equal(t, exp, r)
+		require.Equal(t, "biz", y.CurrentProfile)
+		require.Equal(t, "org2", y.CurrentCloudAuthOrgID)
+		require.Equal(t, CloudAuthSSO, y.CurrentCloudAuthKind)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		y := mine()
+		_, err := y.MergeFile(fs, "/missing.yaml", true)
+		require.Error(t, err)
+		_, err = y.MergeFile(fs, "/newer.yaml", true)
+		require.Error(t, err)
+		require.Equal(t, mine(), y)
+	})
+}
+
+func TestRpkYamlToJSON(t *testing.T) {
+	y := RpkYaml{
+		Version:        6,
+		Globals:        RpkGlobals{CommandTimeout: Duration{10 * time.Second}},
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{
+			{
+				Name:     "foo",
+				KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}, SASL: &SASL{User: "user", Password: "pass"}},
+				AdminAPI: RpkAdminAPI{TLS: &TLS{}},
+			},
+			{
+				Name:         "bar",
+				FromCloud:    true,
+				CloudCluster: RpkCloudCluster{ClusterID: "id", AuthOrgID: "org", AuthKind: CloudAuthSSO},
+			},
+		},
+		CloudAuths: []RpkCloudAuth{{Name: "sso", OrgID: "org", Kind: CloudAuthSSO, AuthToken: "token"}},
+	}
+	j, err := y.ToJSON()
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(j, &raw))
+	profiles := raw["profiles"].([]any)
+	foo := profiles[0].(map[string]any)
+	require.NotContains(t, foo, "cloud_cluster")
+	require.Equal(t, map[string]any{"user": "user", "password": "pass"}, foo["kafka_api"].(map[string]any)["sasl"])
+	require.Contains(t, profiles[1].(map[string]any), "cloud_cluster")
+	require.NotContains(t, raw["cloud_auth"].([]any)[0].(map[string]any), "client_secret")
+
+	var fromJSON, fromYAML RpkYaml
+	require.NoError(t, json.Unmarshal(j, &fromJSON))
+	b, err := yaml.Marshal(y)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(b, &fromYAML))
+	require.Equal(t, fromYAML, fromJSON)
+}
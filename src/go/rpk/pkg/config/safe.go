@@ -0,0 +1,83 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"sync"
+
+	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
+)
+
+// SafeRpkYaml wraps an RpkYaml for concurrent use by multiple goroutines.
+// Profiles are returned as copies, so callers can never race with a
+// concurrent update.
+type SafeRpkYaml struct {
+	mu sync.RWMutex
+	y  RpkYaml
+}
+
+// NewSafeRpkYaml returns a SafeRpkYaml wrapping a copy of y.
+func NewSafeRpkYaml(y *RpkYaml) *SafeRpkYaml {
+	return &SafeRpkYaml{y: y.Clone()}
+}
+
+// Clone returns a copy of the wrapped RpkYaml.
+func (s *SafeRpkYaml) Clone() RpkYaml {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.y.Clone()
+}
+
+// Profile returns a copy of the given profile, and whether it exists.
+func (s *SafeRpkYaml) Profile(name string) (RpkProfile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p := s.y.Profile(name)
+	if p == nil {
+		return RpkProfile{}, false
+	}
+	return p.deepCopy(), true
+}
+
+// PushProfile is RpkYaml.PushProfile; the returned auths are copies.
+func (s *SafeRpkYaml) PushProfile(p RpkProfile) (priorAuth, currentAuth *RpkCloudAuth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	priorAuth, currentAuth = s.y.PushProfile(p.deepCopy())
+	dup := func(a *RpkCloudAuth) *RpkCloudAuth {
+		if a == nil {
+			return nil
+		}
+		d := *a
+		d.Extra = maps.Clone(a.Extra)
+		return &d
+	}
+	return dup(priorAuth), dup(currentAuth)
+}
+
+// SetCurrentProfile is RpkYaml.SetCurrentProfile, returning a copy of the
+// newly selected profile.
+func (s *SafeRpkYaml) SetCurrentProfile(name string) (RpkProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.y.SetCurrentProfile(name)
+	if err != nil {
+		return RpkProfile{}, err
+	}
+	return p.deepCopy(), nil
+}
+
+// Write is RpkYaml.Write.
+func (s *SafeRpkYaml) Write(fs afero.Fs) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.y.Write(fs)
+}
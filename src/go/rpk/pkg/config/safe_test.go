@@ -0,0 +1,73 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSafeRpkYaml is most useful when run with -race.
+func TestSafeRpkYaml(t *testing.T) {
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "base",
+		Profiles: []RpkProfile{{
+			Name:     "base",
+			KafkaAPI: RpkKafkaAPI{Brokers: []string{"127.0.0.1:9092"}},
+		}},
+		fileLocation: "/rpk.yaml",
+	}
+	s := NewSafeRpkYaml(&y)
+	fs := afero.NewMemMapFs()
+
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("p%d", i)
+			s.PushProfile(RpkProfile{Name: name, KafkaAPI: RpkKafkaAPI{Brokers: []string{name + ":9092"}}})
+			for j := 0; j < 20; j++ {
+				p, ok := s.Profile("base")
+				if !ok {
+					t.Error("missing base profile")
+					return
+				}
+				p.KafkaAPI.Brokers[0] = "modified" // copies must not alias the wrapped config
+				if _, err := s.SetCurrentProfile(name); err != nil {
+					t.Error(err)
+				}
+				if err := s.Write(fs); err != nil {
+					t.Error(err)
+				}
+				_ = s.Clone()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	base, ok := s.Profile("base")
+	require.True(t, ok)
+	require.Equal(t, []string{"127.0.0.1:9092"}, base.KafkaAPI.Brokers)
+	final := s.Clone()
+	require.Len(t, final.Profiles, workers+1)
+
+	_, err := s.SetCurrentProfile("missing")
+	require.True(t, errors.Is(err, ErrProfileNotFound))
+	_, ok = s.Profile("missing")
+	require.False(t, ok)
+}
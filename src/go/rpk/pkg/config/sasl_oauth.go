@@ -0,0 +1,111 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// DefaultTokenCommandTimeout is how long a SASL OAUTHBEARER token command may
+// run if kafka_api.sasl.oauth.timeout is unset.
+const DefaultTokenCommandTimeout = 10 * time.Second
+
+// tokenExpirySlack is how long before a token's expiry we run the token
+// command again, so that a token does not expire while rpk is using it.
+const tokenExpirySlack = 30 * time.Second
+
+// OAuthTokenSource returns OAUTHBEARER tokens from a SASLOAuth token command.
+// Tokens that are JWTs with an expiry are cached until shortly before they
+// expire; any other token is fetched again every time it is requested. An
+// OAuthTokenSource is safe for concurrent use.
+type OAuthTokenSource struct {
+	command string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	token string
+	exp   time.Time
+}
+
+// NewOAuthTokenSource returns a token source for the given OAuth config, which
+// must have a TokenCommand.
+func NewOAuthTokenSource(o *SASLOAuth) (*OAuthTokenSource, error) {
+	if o == nil || o.TokenCommand == "" {
+		return nil, errors.New("missing kafka_api.sasl.oauth.token_command")
+	}
+	timeout := o.Timeout.Duration
+	if timeout == 0 {
+		timeout = DefaultTokenCommandTimeout
+	}
+	return &OAuthTokenSource{command: o.TokenCommand, timeout: timeout}, nil
+}
+
+// Token returns the cached token if it has not expired, and otherwise runs the
+// token command and returns what it prints to stdout.
+func (s *OAuthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Add(tokenExpirySlack).Before(s.exp) {
+		return s.token, nil
+	}
+	token, err := s.run(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.exp = token, time.Time{}
+	if parsed, err := jwt.Parse([]byte(token)); err == nil {
+		s.exp = parsed.Expiration()
+	}
+	return token, nil
+}
+
+func (s *OAuthTokenSource) run(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, s.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second // do not wait on children still holding stdout after we kill the shell
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", s.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("sasl oauth token command failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("sasl oauth token command failed: %v", err)
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("sasl oauth token command printed no token")
+	}
+	return token, nil
+}
+
+// shellCommand returns a command that runs command in the platform's shell:
+// cmd on Windows, and /bin/sh everywhere else.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho run >> "+calls+"\n"+body+"\n"), 0o755))
+		return path
+	}
+	numCalls := func() int {
+		raw, _ := os.ReadFile(calls)
+		return strings.Count(string(raw), "run")
+	}
+	sign := func(exp time.Time) string {
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.ExpirationKey, exp))
+		signed, err := jwt.Sign(tok, jwa.HS256, []byte("key"))
+		require.NoError(t, err)
+		return string(signed)
+	}
+	ctx := context.Background()
+
+	t.Run("loaded from rpk.yaml", func(t *testing.T) {
+		fs := testfs.FromMap(map[string]testfs.Fmode{
+			"/etc/rpk/rpk.yaml": {Mode: 0o644, Contents: `version: 8
+current_profile: foo
+profiles:
+    - name: foo
+      kafka_api:
+        sasl:
+            mechanism: oauthbearer
+            oauth:
+                token_command: ` + script("echo.sh", "echo static-token") + `
+                timeout: 5s
+`},
+		})
+		cfg, err := (&Params{ConfigFlag: "/etc/rpk/rpk.yaml"}).Load(fs)
+		require.NoError(t, err)
+		sasl := cfg.VirtualProfile().KafkaAPI.SASL
+		require.Equal(t, "OAUTHBEARER", sasl.Mechanism)
+		require.Equal(t, Duration{5 * time.Second}, sasl.OAuth.Timeout)
+
+		src, err := NewOAuthTokenSource(sasl.OAuth)
+		require.NoError(t, err)
+		token, err := src.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "static-token", token)
+
+		// A token we cannot see the expiry of is fetched every time.
+		before := numCalls()
+		_, err = src.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, before+1, numCalls())
+	})
+
+	t.Run("jwt cached until expiry", func(t *testing.T) {
+		valid := sign(time.Now().Add(time.Hour))
+		src, err := NewOAuthTokenSource(&SASLOAuth{TokenCommand: script("valid.sh", "echo "+valid)})
+		require.NoError(t, err)
+		before := numCalls()
+		for i := 0; i < 3; i++ {
+			token, err := src.Token(ctx)
+			require.NoError(t, err)
+			require.Equal(t, valid, token)
+		}
+		require.Equal(t, before+1, numCalls())
+
+		expiring := sign(time.Now().Add(time.Second))
+		src, err = NewOAuthTokenSource(&SASLOAuth{TokenCommand: script("expiring.sh", "echo "+expiring)})
+		require.NoError(t, err)
+		before = numCalls()
+		for i := 0; i < 2; i++ {
+			_, err := src.Token(ctx)
+			require.NoError(t, err)
+		}
+		require.Equal(t, before+2, numCalls())
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		_, err := NewOAuthTokenSource(&SASLOAuth{})
+		require.Error(t, err)
+
+		src, err := NewOAuthTokenSource(&SASLOAuth{TokenCommand: script("fail.sh", "echo 'no credentials' >&2; exit 3")})
+		require.NoError(t, err)
+		_, err = src.Token(ctx)
+		require.ErrorContains(t, err, "no credentials")
+
+		src, err = NewOAuthTokenSource(&SASLOAuth{TokenCommand: script("empty.sh", "true")})
+		require.NoError(t, err)
+		_, err = src.Token(ctx)
+		require.ErrorContains(t, err, "no token")
+
+		src, err = NewOAuthTokenSource(&SASLOAuth{
+			TokenCommand: script("slow.sh", "sleep 10"),
+			Timeout:      Duration{100 * time.Millisecond},
+		})
+		require.NoError(t, err)
+		start := time.Now()
+		_, err = src.Token(ctx)
+		require.ErrorContains(t, err, "timed out")
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+}
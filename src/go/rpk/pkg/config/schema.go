@@ -0,0 +1,138 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonSchema is the subset of a JSON Schema that we generate.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// Fields that must be set, keyed by type and then by yaml name. Every other
+// field is optional: rpk defaults anything that is missing.
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(RpkYaml{}):      {"version"},
+	reflect.TypeOf(RpkProfile{}):   {"name"},
+	reflect.TypeOf(RpkCloudAuth{}): {"name"},
+}
+
+// Fields that only accept specific values, keyed by type and then by yaml
+// name.
+var schemaEnums = map[reflect.Type]map[string][]string{
+	reflect.TypeOf(SASL{}):         {"mechanism": append([]string{""}, saslMechanisms...)},
+	reflect.TypeOf(RpkCloudAuth{}): {"kind": {CloudAuthUninitialized, CloudAuthSSO, CloudAuthClientCredentials}},
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timestampType       = reflect.TypeOf(Timestamp{})
+)
+
+// JSONSchema returns a JSON Schema describing the rpk.yaml format, for editors
+// to validate hand written rpk.yaml files against. The schema is generated
+// from the RpkYaml struct with reflection and is always in sync with it.
+func JSONSchema() ([]byte, error) {
+	s, err := typeSchema(reflect.TypeOf(RpkYaml{}))
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = "rpk.yaml"
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func typeSchema(typ reflect.Type) (*jsonSchema, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	// Types that decode from text are strings, regardless of their kind.
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		s := &jsonSchema{Type: "string"}
+		if typ == timestampType {
+			s.Format = "date-time"
+		}
+		return s, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}, nil
+	case reflect.String:
+		return &jsonSchema{Type: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}, nil
+	case reflect.Interface:
+		return &jsonSchema{}, nil // any value
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", typ.Key())
+		}
+		elem, err := typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: elem}, nil
+	case reflect.Struct:
+		// rest of this function
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+
+	s := &jsonSchema{
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+		Required:   schemaRequired[typ],
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		// Inline fields capture unknown keys, which we already allow.
+		if name == "-" || strings.Contains(opts, "inline") {
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("field %s.%s is missing a yaml tag", typ.Name(), sf.Name)
+		}
+		fs, err := typeSchema(sf.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", typ.Name(), sf.Name, err)
+		}
+		fs.Enum = schemaEnums[typ][name]
+		s.Properties[name] = fs
+	}
+	return s, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	raw, err := JSONSchema()
+	require.NoError(t, err)
+
+	var s jsonSchema
+	require.NoError(t, json.Unmarshal(raw, &s))
+	require.Equal(t, "object", s.Type)
+	require.Contains(t, s.Properties, "version")
+	require.Contains(t, s.Properties, "profiles")
+	require.Equal(t, []string{"version"}, s.Required)
+	profile := s.Properties["profiles"].Items
+	require.Equal(t, []string{"name"}, profile.Required)
+	require.Contains(t, profile.Properties["kafka_api"].Properties["sasl"].Properties["mechanism"].Enum, "SCRAM-SHA-256")
+	require.Equal(t, "string", profile.Properties["kafka_api"].Properties["dial_timeout"].Type)
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	require.NoError(t, err)
+	c := jsonschema.NewCompiler()
+	require.NoError(t, c.AddResource("rpk.json", doc))
+	schema, err := c.Compile("rpk.json")
+	require.NoError(t, err)
+
+	validate := func(y RpkYaml) error {
+		j, err := y.ToJSON()
+		require.NoError(t, err)
+		inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(j))
+		require.NoError(t, err)
+		return schema.Validate(inst)
+	}
+	y := RpkYaml{
+		Version:        currentRpkYAMLVersion,
+		CurrentProfile: "foo",
+		Profiles: []RpkProfile{{
+			Name:      "foo",
+			CreatedAt: Timestamp{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			KafkaAPI: RpkKafkaAPI{
+				Brokers:     []string{"127.0.0.1:9092"},
+				SASL:        &SASL{User: "user", Password: "pass", Mechanism: "SCRAM-SHA-512"},
+				DialTimeout: Duration{time.Second},
+			},
+			Labels: map[string]string{"env": "dev"},
+		}},
+		CloudAuths: []RpkCloudAuth{{Name: "auth", OrgID: "org", Kind: CloudAuthSSO}},
+	}
+	require.NoError(t, validate(y))
+
+	y.Profiles[0].KafkaAPI.SASL.Mechanism = "NOT-A-MECHANISM"
+	require.Error(t, validate(y))
+}
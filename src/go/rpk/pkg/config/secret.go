@@ -0,0 +1,58 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	secretFilePrefix    = "file:"
+	secretEnvPrefix     = "env:"
+	secretLiteralPrefix = "literal:"
+)
+
+// ResolveSecret resolves a secret that may be a reference rather than the
+// secret itself:
+//
+//   - "file:<path>" reads the secret from the file at path, trimming any
+//     trailing newline.
+//   - "env:<VAR>" reads the secret from the environment variable VAR, which
+//     must be set.
+//   - "literal:<value>" is the literal value, allowing secrets that themselves
+//     start with "file:", "env:", or "literal:".
+//
+// Any other value is returned as is.
+func ResolveSecret(fs afero.Fs, secret string) (string, error) {
+	switch {
+	case strings.HasPrefix(secret, secretFilePrefix):
+		path := strings.TrimPrefix(secret, secretFilePrefix)
+		raw, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(raw), "\r\n"), nil
+	case strings.HasPrefix(secret, secretEnvPrefix):
+		name := strings.TrimPrefix(secret, secretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %q is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(secret, secretLiteralPrefix):
+		return strings.TrimPrefix(secret, secretLiteralPrefix), nil
+	default:
+		return secret, nil
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/secret", []byte("from-file\n"), 0o600))
+	t.Setenv("RPK_TEST_SECRET", "from-env")
+
+	for _, test := range []struct {
+		name   string
+		secret string
+		exp    string
+		expErr bool
+	}{
+		{name: "empty", secret: "", exp: ""},
+		{name: "plain", secret: "hunter2", exp: "hunter2"},
+		{name: "file", secret: "file:/secret", exp: "from-file"},
+		{name: "missing file", secret: "file:/missing", expErr: true},
+		{name: "env", secret: "env:RPK_TEST_SECRET", exp: "from-env"},
+		{name: "unset env", secret: "env:RPK_TEST_SECRET_UNSET", expErr: true},
+		{name: "escaped file", secret: "literal:file:/secret", exp: "file:/secret"},
+		{name: "escaped literal", secret: "literal:literal:x", exp: "literal:x"},
+		{name: "prefix in middle", secret: "pass-file:x", exp: "pass-file:x"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ResolveSecret(fs, test.secret)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.exp, got)
+		})
+	}
+}
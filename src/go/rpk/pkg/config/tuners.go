@@ -0,0 +1,86 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// tunerToggles returns every tuner name and whether the tuner is enabled, in
+// the order the tuners are defined in RpkNodeTuners.
+func (t RpkNodeTuners) tunerToggles() []struct {
+	name    string
+	enabled bool
+} {
+	return []struct {
+		name    string
+		enabled bool
+	}{
+		{"net", t.TuneNetwork},
+		{"disk_scheduler", t.TuneDiskScheduler},
+		{"disk_nomerges", t.TuneNomerges},
+		{"disk_write_cache", t.TuneDiskWriteCache},
+		{"disk_irq", t.TuneDiskIrq},
+		{"fstrim", t.TuneFstrim},
+		{"cpu", t.TuneCPU},
+		{"aio_events", t.TuneAioEvents},
+		{"clocksource", t.TuneClocksource},
+		{"swappiness", t.TuneSwappiness},
+		{"transparent_hugepages", t.TuneTransparentHugePages},
+		{"coredump", t.TuneCoredump},
+		{"ballast_file", t.TuneBallastFile},
+	}
+}
+
+// IsEnabled returns whether the tuner with the given name is enabled, or an
+// error if name is not a known tuner.
+func (t RpkNodeTuners) IsEnabled(name string) (bool, error) {
+	for _, toggle := range t.tunerToggles() {
+		if toggle.name == name {
+			return toggle.enabled, nil
+		}
+	}
+	return false, fmt.Errorf("unknown tuner %q", name)
+}
+
+// Enabled returns the names of the tuners that are enabled.
+func (t RpkNodeTuners) Enabled() []string {
+	var enabled []string
+	for _, toggle := range t.tunerToggles() {
+		if toggle.enabled {
+			enabled = append(enabled, toggle.name)
+		}
+	}
+	return enabled
+}
+
+// Validate returns an error if any tuner setting is invalid: the ballast
+// file size must be a positive size such as "1GiB", and the well known io
+// must have the format <vendor>:<vm type>:<storage type>.
+func (t RpkNodeTuners) Validate() error {
+	var errs []error
+	if t.BallastFileSize != "" {
+		size, err := units.FromHumanSize(t.BallastFileSize)
+		if err != nil || size <= 0 {
+			errs = append(errs, fmt.Errorf("invalid ballast_file_size %q: must be a positive size, such as %q", t.BallastFileSize, DefaultBallastFileSize))
+		}
+	}
+	if t.WellKnownIo != "" {
+		tokens := strings.Split(t.WellKnownIo, ":")
+		if len(tokens) != 3 || tokens[0] == "" || tokens[1] == "" || tokens[2] == "" {
+			errs = append(errs, fmt.Errorf("invalid well_known_io %q: must have the format <vendor>:<vm type>:<storage type>", t.WellKnownIo))
+		}
+	}
+	return errors.Join(errs...)
+}
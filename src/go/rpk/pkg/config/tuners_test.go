@@ -0,0 +1,55 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpkNodeTuners(t *testing.T) {
+	valid := RpkNodeTuners{
+		TuneNetwork:     true,
+		TuneCPU:         true,
+		TuneBallastFile: true,
+		BallastFilePath: "/var/lib/redpanda/data/ballast",
+		BallastFileSize: "2GiB",
+		WellKnownIo:     "aws:i3.xlarge:default",
+	}
+	require.NoError(t, valid.Validate())
+	require.Equal(t, []string{"net", "cpu", "ballast_file"}, valid.Enabled())
+	require.NoError(t, RpkNodeTuners{}.Validate())
+	require.Empty(t, RpkNodeTuners{}.Enabled())
+
+	enabled, err := valid.IsEnabled("cpu")
+	require.NoError(t, err)
+	require.True(t, enabled)
+	enabled, err = valid.IsEnabled("fstrim")
+	require.NoError(t, err)
+	require.False(t, enabled)
+	_, err = valid.IsEnabled("not_a_tuner")
+	require.Error(t, err)
+
+	for _, test := range []struct {
+		name   string
+		tuners RpkNodeTuners
+	}{
+		{"negative ballast size", RpkNodeTuners{BallastFileSize: "-1GiB"}},
+		{"zero ballast size", RpkNodeTuners{BallastFileSize: "0"}},
+		{"unparseable ballast size", RpkNodeTuners{BallastFileSize: "big"}},
+		{"bad well known io", RpkNodeTuners{WellKnownIo: "aws:i3.xlarge"}},
+		{"empty well known io token", RpkNodeTuners{WellKnownIo: "aws::default"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Error(t, test.tuners.Validate())
+		})
+	}
+}
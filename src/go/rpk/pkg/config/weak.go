@@ -477,9 +477,13 @@ func (rpkc *RpkNodeConfig) UnmarshalYAML(n *yaml.Node) error {
 
 func (r *RpkKafkaAPI) UnmarshalYAML(n *yaml.Node) error {
 	var internal struct {
-		Brokers weakStringArray `yaml:"brokers"`
-		TLS     *TLS            `yaml:"tls"`
-		SASL    *SASL           `yaml:"sasl"`
+		Brokers                weakStringArray    `yaml:"brokers"`
+		TLS                    *TLS               `yaml:"tls"`
+		SASL                   *SASL              `yaml:"sasl"`
+		BrokerSASL             map[string]*SASL   `yaml:"broker_sasl"`
+		DialTimeout            Duration           `yaml:"dial_timeout"`
+		RequestTimeoutOverhead Duration           `yaml:"request_timeout_overhead"`
+		ClientTuning           *KafkaClientTuning `yaml:"client_tuning"`
 	}
 	if err := n.Decode(&internal); err != nil {
 		return err
@@ -487,19 +491,27 @@ func (r *RpkKafkaAPI) UnmarshalYAML(n *yaml.Node) error {
 	r.Brokers = internal.Brokers
 	r.TLS = internal.TLS
 	r.SASL = internal.SASL
+	r.BrokerSASL = internal.BrokerSASL
+	r.DialTimeout = internal.DialTimeout
+	r.RequestTimeoutOverhead = internal.RequestTimeoutOverhead
+	r.ClientTuning = internal.ClientTuning
 	return nil
 }
 
 func (r *RpkAdminAPI) UnmarshalYAML(n *yaml.Node) error {
 	var internal struct {
-		Addresses weakStringArray `yaml:"addresses"`
-		TLS       *TLS            `yaml:"tls"`
+		Addresses      weakStringArray `yaml:"addresses"`
+		TLS            *TLS            `yaml:"tls"`
+		DialTimeout    Duration        `yaml:"dial_timeout"`
+		RequestTimeout Duration        `yaml:"request_timeout"`
 	}
 	if err := n.Decode(&internal); err != nil {
 		return err
 	}
 	r.Addresses = internal.Addresses
 	r.TLS = internal.TLS
+	r.DialTimeout = internal.DialTimeout
+	r.RequestTimeout = internal.RequestTimeout
 	return nil
 }
 
@@ -677,6 +689,7 @@ func (t *TLS) UnmarshalYAML(n *yaml.Node) error {
 		CAFile             weakString `yaml:"ca_file"`
 		InsecureSkipVerify bool       `yaml:"insecure_skip_verify"`
 		TruststoreFile     weakString `yaml:"truststore_file"` // BACKCOMPAT 23-05-01 we deserialize truststore_file into ca_file
+		ServerName         weakString `yaml:"server_name"`
 	}
 
 	if err := n.Decode(&internal); err != nil {
@@ -686,6 +699,7 @@ func (t *TLS) UnmarshalYAML(n *yaml.Node) error {
 	t.CertFile = string(internal.CertFile)
 	t.TruststoreFile = string(internal.TruststoreFile)
 	t.InsecureSkipVerify = internal.InsecureSkipVerify
+	t.ServerName = string(internal.ServerName)
 	if internal.CAFile != "" {
 		t.TruststoreFile = string(internal.CAFile)
 	}
@@ -694,16 +708,20 @@ func (t *TLS) UnmarshalYAML(n *yaml.Node) error {
 
 func (s *SASL) UnmarshalYAML(n *yaml.Node) error {
 	var internal struct {
-		User      weakString `yaml:"user"`
-		Password  weakString `yaml:"password"`
-		Mechanism weakString `yaml:"mechanism"`
-		Type      weakString `yaml:"type"` // BACKCOMPAT 23-05-24 we deserialize type into mechanism
+		User      weakString  `yaml:"user"`
+		Password  weakString  `yaml:"password"`
+		Mechanism weakString  `yaml:"mechanism"`
+		Type      weakString  `yaml:"type"` // BACKCOMPAT 23-05-24 we deserialize type into mechanism
+		OAuth     *SASLOAuth  `yaml:"oauth"`
+		GSSAPI    *SASLGSSAPI `yaml:"gssapi"`
 	}
 	if err := n.Decode(&internal); err != nil {
 		return err
 	}
 	s.User = string(internal.User)
 	s.Password = string(internal.Password)
+	s.OAuth = internal.OAuth
+	s.GSSAPI = internal.GSSAPI
 	s.Mechanism = string(internal.Type)
 	if internal.Mechanism != "" {
 		s.Mechanism = string(internal.Mechanism)
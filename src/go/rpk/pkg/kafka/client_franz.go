@@ -10,11 +10,15 @@
 package kafka
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/adminapi"
@@ -26,15 +30,39 @@ import (
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/twmb/franz-go/pkg/sasl"
 	koauth "github.com/twmb/franz-go/pkg/sasl/oauth"
 	"github.com/twmb/franz-go/pkg/sasl/plain"
 	"github.com/twmb/franz-go/pkg/sasl/scram"
 	"github.com/twmb/franz-go/plugin/kzap"
+	"golang.org/x/exp/maps"
 )
 
+// noKeepAliveDialer returns a dial function that disables TCP keep-alives,
+// and that performs a TLS handshake if tc is non-nil. Like kgo's
+// DialTLSConfig, the server name defaults to the host being dialed.
+func noKeepAliveDialer(timeout time.Duration, tc *tls.Config) func(context.Context, string, string) (net.Conn, error) {
+	nd := &net.Dialer{Timeout: timeout, KeepAlive: -1}
+	if tc == nil {
+		return nd.DialContext
+	}
+	return func(ctx context.Context, network, host string) (net.Conn, error) {
+		c := tc.Clone()
+		if c.ServerName == "" {
+			server, _, err := net.SplitHostPort(host)
+			if err != nil {
+				return nil, fmt.Errorf("unable to split host:port for dialing: %w", err)
+			}
+			c.ServerName = server
+		}
+		return (&tls.Dialer{NetDialer: nd, Config: c}).DialContext(ctx, network, host)
+	}
+}
+
 // NewFranzClient returns a franz-go based kafka client.
 func NewFranzClient(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*kgo.Client, error) {
-	k := &p.KafkaAPI
+	ek := p.EffectiveKafkaAPI()
+	k := &ek
 
 	d := p.Defaults()
 	if len(k.Brokers) == 0 && d.NoDefaultCluster {
@@ -64,8 +92,8 @@ func NewFranzClient(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*k
 		// once we support -X and then add these as configurable
 		// options. We cannot be "aggressively" low without override
 		// options because we may affect end users.
-		kgo.DialTimeout(3 * time.Second),
-		kgo.RequestTimeoutOverhead(5 * time.Second),
+		kgo.DialTimeout(config.DefaultKafkaDialTimeout),
+		kgo.RequestTimeoutOverhead(config.DefaultKafkaRequestTimeoutOverhead),
 		kgo.RetryTimeout(11 * time.Second), // if updating this, update below's SetTimeoutMillis
 
 		// Redpanda may indicate one leader just before rebalancing the
@@ -80,13 +108,13 @@ func NewFranzClient(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*k
 
 	// We apply user overrides after our defaults above. Options are
 	// applied in order, so appending at the end overrides anything
-	// above.
-	if d := d.DialTimeout; d.Duration != 0 {
-		opts = append(opts, kgo.DialTimeout(d.Duration))
-	}
-	if d := d.RequestTimeoutOverhead; d.Duration != 0 {
-		opts = append(opts, kgo.RequestTimeoutOverhead(d.Duration))
-	}
+	// above. The effective Kafka API has already resolved the dial
+	// and request timeouts from the profile, globals, and defaults.
+	opts = append(opts,
+		kgo.DialTimeout(k.DialTimeout.Duration),
+		kgo.RequestTimeoutOverhead(k.RequestTimeoutOverhead.Duration),
+		kgo.ConnIdleTimeout(k.ClientTuning.ConnIdleTimeout.Duration),
+	)
 	if d := d.RetryTimeout; d.Duration != 0 {
 		opts = append(opts, kgo.RetryTimeout(d.Duration))
 	}
@@ -97,55 +125,31 @@ func NewFranzClient(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*k
 		opts = append(opts, kgo.ClientID(id))
 	}
 
-	if k.SASL != nil {
-		if k.SASL.Mechanism == adminapi.CloudOIDC {
-			a := p.CurrentAuth()
-			if a == nil || a.AuthToken == "" {
-				return nil, errors.New("no current auth found, please login with 'rpk cloud login'")
-			}
-			expired, err := oauth.ValidateToken(
-				a.AuthToken,
-				auth0.NewClient(p.DevOverrides()).Audience(),
-				a.ClientID,
-			)
-			if err != nil {
-				if errors.Is(err, oauth.ErrMissingToken) {
-					return nil, err
-				}
-				return nil, fmt.Errorf("unable to validate cloud token, please login again using 'rpk cloud login': %v", err)
-			}
-			if expired {
-				return nil, fmt.Errorf("your cloud token has expired, please login again using 'rpk cloud login'")
-			}
-			opts = append(opts, kgo.SASL((koauth.Auth{
-				Token: a.AuthToken,
-			}).AsMechanism()))
-		} else {
-			a := scram.Auth{
-				User: k.SASL.User,
-				Pass: k.SASL.Password,
-			}
-			switch name := strings.ToUpper(k.SASL.Mechanism); name {
-			case "SCRAM-SHA-256", "": // we default to SCRAM-SHA-256 -- people commonly specify user & pass without --sasl-mechanism
-				opts = append(opts, kgo.SASL(a.AsSha256Mechanism()))
-			case "SCRAM-SHA-512":
-				opts = append(opts, kgo.SASL(a.AsSha512Mechanism()))
-			case "PLAIN":
-				opts = append(opts, kgo.SASL((&plain.Auth{
-					User: k.SASL.User,
-					Pass: k.SASL.Password,
-				}).AsMechanism()))
-			default:
-				return nil, fmt.Errorf("unknown SASL mechanism %q, supported: [SCRAM-SHA-256, SCRAM-SHA-512, PLAIN]", name)
-			}
+	if len(k.BrokerSASL) > 0 {
+		m, err := newBrokerSASL(p, k)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			opts = append(opts, kgo.SASL(m))
 		}
+	} else if k.SASL != nil {
+		m, err := saslMechanism(p, k.SASL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(m))
 	}
 
 	tc, err := k.TLS.Config(fs)
 	if err != nil {
 		return nil, err
 	}
-	if tc != nil {
+	if !*k.ClientTuning.KeepAlive {
+		// kgo does not allow a custom dialer alongside DialTLSConfig,
+		// so we dial TLS ourselves when keep-alives are disabled.
+		opts = append(opts, kgo.Dialer(noKeepAliveDialer(k.DialTimeout.Duration, tc)))
+	} else if tc != nil {
 		opts = append(opts, kgo.DialTLSConfig(tc))
 	}
 	opts = append(opts, kgo.WithLogger(kzap.New(p.Logger())))
@@ -154,6 +158,149 @@ func NewFranzClient(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*k
 	return kgo.NewClient(opts...)
 }
 
+// saslMechanism returns the SASL mechanism that authenticates with s.
+func saslMechanism(p *config.RpkProfile, s *config.SASL) (sasl.Mechanism, error) {
+	if s.Mechanism == adminapi.CloudOIDC {
+		a := p.CurrentAuth()
+		if a == nil || a.AuthToken == "" {
+			return nil, errors.New("no current auth found, please login with 'rpk cloud login'")
+		}
+		expired, err := oauth.ValidateToken(
+			a.AuthToken,
+			auth0.NewClient(p.DevOverrides()).Audience(),
+			a.ClientID,
+		)
+		if err != nil {
+			if errors.Is(err, oauth.ErrMissingToken) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unable to validate cloud token, please login again using 'rpk cloud login': %v", err)
+		}
+		if expired {
+			return nil, fmt.Errorf("your cloud token has expired, please login again using 'rpk cloud login'")
+		}
+		return (koauth.Auth{
+			Token: a.AuthToken,
+		}).AsMechanism(), nil
+	}
+	a := scram.Auth{
+		User: s.User,
+		Pass: s.Password,
+	}
+	switch name := strings.ToUpper(s.Mechanism); name {
+	case "SCRAM-SHA-256", "": // we default to SCRAM-SHA-256 -- people commonly specify user & pass without --sasl-mechanism
+		return a.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		return a.AsSha512Mechanism(), nil
+	case "PLAIN":
+		return (&plain.Auth{
+			User: s.User,
+			Pass: s.Password,
+		}).AsMechanism(), nil
+	case "GSSAPI":
+		return nil, errors.New("SASL mechanism GSSAPI is not supported by rpk's Kafka client yet")
+	case "OAUTHBEARER":
+		src, err := config.NewOAuthTokenSource(s.OAuth)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use SASL mechanism OAUTHBEARER: %v", err)
+		}
+		// The token is requested on every connection, which
+		// refreshes it once it expires.
+		return koauth.Oauth(func(ctx context.Context) (koauth.Auth, error) {
+			token, err := src.Token(ctx)
+			return koauth.Auth{Token: token}, err
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q, supported: [SCRAM-SHA-256, SCRAM-SHA-512, PLAIN, OAUTHBEARER]", name)
+	}
+}
+
+// brokerSASL is a SASL mechanism that authenticates with the credentials for
+// the broker being connected to, see RpkKafkaAPI.BrokerSASL. The broker is
+// the address the client dials: a seed address for seed brokers, and the
+// address advertised in metadata for every other broker.
+//
+// The client negotiates the mechanism name before authenticating and uses
+// SASL on every connection, which limits what overrides can do: every broker
+// must use the same mechanism, and a broker without an override must be
+// covered by kafka_api.sasl, there is no falling back to no SASL. Mechanisms
+// are built once per broker, so that OAUTHBEARER token sources are reused
+// across connections.
+type brokerSASL struct {
+	k    *config.RpkKafkaAPI
+	p    *config.RpkProfile
+	name string
+
+	mu    sync.Mutex
+	hosts map[string]sasl.Mechanism
+}
+
+// newBrokerSASL returns a brokerSASL for the profile's Kafka API k, or nil if
+// neither the profile nor any broker has SASL configured. Every configuration
+// is built up front, such that invalid configurations, including brokers
+// that use different mechanisms, fail before connecting.
+func newBrokerSASL(p *config.RpkProfile, k *config.RpkKafkaAPI) (*brokerSASL, error) {
+	b := &brokerSASL{k: k, p: p, hosts: make(map[string]sasl.Mechanism)}
+	var from string
+	use := func(what string, m sasl.Mechanism) error {
+		if b.name != "" && b.name != m.Name() {
+			return fmt.Errorf("%s uses SASL mechanism %s but %s uses %s, every broker must use the same mechanism", what, m.Name(), from, b.name)
+		}
+		b.name, from = m.Name(), what
+		return nil
+	}
+	if k.SASL != nil {
+		m, err := saslMechanism(p, k.SASL)
+		if err != nil {
+			return nil, err
+		}
+		if err := use("kafka_api.sasl", m); err != nil {
+			return nil, err
+		}
+	}
+	addrs := maps.Keys(k.BrokerSASL)
+	sort.Strings(addrs)
+	for _, addr := range addrs {
+		s := k.SASLFor(addr)
+		if s == nil {
+			continue
+		}
+		m, err := saslMechanism(p, s)
+		if err != nil {
+			return nil, fmt.Errorf("broker %s: %w", addr, err)
+		}
+		if err := use(fmt.Sprintf("broker %s", addr), m); err != nil {
+			return nil, err
+		}
+	}
+	if b.name == "" {
+		return nil, nil
+	}
+	return b, nil
+}
+
+func (b *brokerSASL) Name() string { return b.name }
+
+func (b *brokerSASL) Authenticate(ctx context.Context, host string) (sasl.Session, []byte, error) {
+	b.mu.Lock()
+	m, ok := b.hosts[host]
+	if !ok {
+		s := b.k.SASLFor(host)
+		if s == nil {
+			b.mu.Unlock()
+			return nil, nil, fmt.Errorf("no SASL credentials for broker %s: kafka_api.sasl is unset and the broker has no kafka_api.broker_sasl override; SASL is used for every broker once any broker uses it, so either set kafka_api.sasl or add an override for the address the broker advertises", host)
+		}
+		var err error
+		if m, err = saslMechanism(b.p, s); err != nil {
+			b.mu.Unlock()
+			return nil, nil, err
+		}
+		b.hosts[host] = m
+	}
+	b.mu.Unlock()
+	return m.Authenticate(ctx, host)
+}
+
 // NewAdmin returns a franz-go admin client.
 func NewAdmin(fs afero.Fs, p *config.RpkProfile, extraOpts ...kgo.Opt) (*kadm.Client, error) {
 	cl, err := NewFranzClient(fs, p, extraOpts...)
@@ -0,0 +1,92 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerSASL(t *testing.T) {
+	plain := func(user, pass string) *config.SASL {
+		return &config.SASL{Mechanism: "PLAIN", User: user, Password: pass}
+	}
+	// authAs returns the PLAIN message the client sends to host, which
+	// contains the user and password used for the broker.
+	authAs := func(t *testing.T, b *brokerSASL, host string) string {
+		_, msg, err := b.Authenticate(context.Background(), host)
+		require.NoError(t, err)
+		return string(msg)
+	}
+
+	t.Run("advertised hosts", func(t *testing.T) {
+		// The profile only knows a seed address; other brokers are
+		// dialed at the address they advertise in metadata.
+		k := &config.RpkKafkaAPI{
+			Brokers: []string{"seed.example.com:9092"},
+			SASL:    plain("seed", "seed-pass"),
+			BrokerSASL: map[string]*config.SASL{
+				"broker-1.internal:9092": {User: "one", Password: "one-pass"},
+				"broker-2.internal":      {User: "two", Password: "two-pass"},
+			},
+		}
+		b, err := newBrokerSASL(new(config.RpkProfile), k)
+		require.NoError(t, err)
+		require.Equal(t, "PLAIN", b.Name())
+
+		require.Equal(t, "\x00seed\x00seed-pass", authAs(t, b, "seed.example.com:9092"))
+		require.Equal(t, "\x00one\x00one-pass", authAs(t, b, "broker-1.internal:9092"))
+		require.Equal(t, "\x00two\x00two-pass", authAs(t, b, "BROKER-2.internal:9092"))
+		require.Equal(t, "\x00seed\x00seed-pass", authAs(t, b, "broker-3.internal:9092"))
+	})
+
+	t.Run("no SASL without an override", func(t *testing.T) {
+		k := &config.RpkKafkaAPI{
+			BrokerSASL: map[string]*config.SASL{
+				"broker-1.internal:9092": plain("one", "one-pass"),
+			},
+		}
+		b, err := newBrokerSASL(new(config.RpkProfile), k)
+		require.NoError(t, err)
+		require.Equal(t, "\x00one\x00one-pass", authAs(t, b, "broker-1.internal:9092"))
+		_, _, err = b.Authenticate(context.Background(), "broker-2.internal:9092")
+		require.ErrorContains(t, err, "no SASL credentials for broker broker-2.internal:9092")
+	})
+
+	t.Run("mixed mechanisms", func(t *testing.T) {
+		k := &config.RpkKafkaAPI{
+			BrokerSASL: map[string]*config.SASL{
+				"broker-1.internal:9092": plain("one", "one-pass"),
+				"broker-2.internal:9092": {Mechanism: "SCRAM-SHA-512", User: "two", Password: "two-pass"},
+			},
+		}
+		for range 10 {
+			_, err := newBrokerSASL(new(config.RpkProfile), k)
+			require.EqualError(t, err, "broker broker-2.internal:9092 uses SASL mechanism SCRAM-SHA-512 but broker broker-1.internal:9092 uses PLAIN, every broker must use the same mechanism")
+		}
+	})
+
+	t.Run("GSSAPI is unsupported", func(t *testing.T) {
+		k := &config.RpkKafkaAPI{
+			SASL: &config.SASL{Mechanism: "GSSAPI", GSSAPI: &config.SASLGSSAPI{Username: "rpk", Password: "secret"}},
+		}
+		_, err := newBrokerSASL(new(config.RpkProfile), k)
+		require.EqualError(t, err, "SASL mechanism GSSAPI is not supported by rpk's Kafka client yet")
+	})
+
+	t.Run("no SASL", func(t *testing.T) {
+		b, err := newBrokerSASL(new(config.RpkProfile), new(config.RpkKafkaAPI))
+		require.NoError(t, err)
+		require.Nil(t, b)
+	})
+}
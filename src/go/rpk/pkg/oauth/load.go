@@ -54,7 +54,7 @@ func LoadFlow(ctx context.Context, fs afero.Fs, cfg *config.Config, cl Client, n
 
 	if authVir.HasClientCredentials() {
 		zap.L().Sugar().Debug("logging in using client credential flow")
-		tok, isNewToken, err = ClientCredentialFlow(ctx, cl, authVir, forceReload)
+		tok, isNewToken, err = ClientCredentialFlow(ctx, fs, cl, authVir, forceReload)
 		authKind = config.CloudAuthClientCredentials
 	} else {
 		zap.L().Sugar().Debug("logging in using OAUTH flow")
@@ -117,7 +117,7 @@ func TestLoadFlow(t *testing.T) {
 				hasClientID = true
 			}
 
-			expFile := fmt.Sprintf(`version: 5
+			expFile := fmt.Sprintf(`version: 29
 globals:
     prompt: ""
     no_default_cluster: false
@@ -127,6 +127,7 @@ globals:
     retry_timeout: 0s
     fetch_max_wait: 0s
     kafka_protocol_request_client_id: ""
+    journal: false
 current_profile: ""
 current_cloud_auth_org_id: no-url-org-id
 current_cloud_auth_kind: %[1]s
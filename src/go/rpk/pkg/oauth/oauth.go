@@ -9,6 +9,7 @@ import (
 
 	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/spf13/afero"
 	"go.uber.org/zap"
 )
 
@@ -58,8 +59,9 @@ type (
 
 // ClientCredentialFlow follows the OAuth 2.0 client credential authentication
 // flow. First it validates whether the configuration already have a valid
-// token.
-func ClientCredentialFlow(ctx context.Context, cl Client, auth *config.RpkCloudAuth, forceReload bool) (Token, bool, error) {
+// token. A client secret reference (see config.ResolveSecret) is resolved
+// against fs.
+func ClientCredentialFlow(ctx context.Context, fs afero.Fs, cl Client, auth *config.RpkCloudAuth, forceReload bool) (Token, bool, error) {
 	// We only validate the token if we have the client ID, if one of them is
 	// not present we just start the login flow again.
 	if auth.AuthToken != "" && auth.ClientID != "" && !forceReload {
@@ -74,7 +76,11 @@ func ClientCredentialFlow(ctx context.Context, cl Client, auth *config.RpkCloudA
 		fmt.Println("Your existing authorization token has expired.")
 	}
 	zap.L().Sugar().Debug("Requesting a new authorization token with your client credentials.")
-	t, err := cl.Token(ctx, auth.ClientID, auth.ClientSecret)
+	secret, err := config.ResolveSecret(fs, auth.ClientSecret)
+	if err != nil {
+		return Token{}, false, fmt.Errorf("unable to resolve your client secret: %v", err)
+	}
+	t, err := cl.Token(ctx, auth.ClientID, secret)
 	if err == nil {
 		zap.L().Sugar().Debug("Successfully retrieved a new authorization token.")
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/lestrrat-go/jwx/jwa"
 	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/config"
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/testfs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,6 +66,17 @@ func TestClientCredentialFlow(t *testing.T) {
 		exp      Token
 		expErr   bool
 	}{
+		{
+			name: "client secret reference is resolved",
+			mToken: func(_ context.Context, _, clientSecret string) (Token, error) {
+				if clientSecret != "file-secret" {
+					return Token{}, fmt.Errorf("unexpected client secret %q", clientSecret)
+				}
+				return Token{AccessToken: "token!"}, nil
+			},
+			auth: &config.RpkCloudAuth{ClientID: "id", ClientSecret: "file:/secrets/client"},
+			exp:  Token{AccessToken: "token!"},
+		},
 		{
 			name: "retrieve token -- validate correct endpoint",
 			mToken: func(context.Context, string, string) (Token, error) {
@@ -134,7 +146,10 @@ func TestClientCredentialFlow(t *testing.T) {
 				audience:  tt.audience,
 				mockToken: tt.mToken,
 			}
-			got, _, err := ClientCredentialFlow(context.Background(), cl, tt.auth, false)
+			fs := testfs.FromMap(map[string]testfs.Fmode{
+				"/secrets/client": testfs.RFile("file-secret\n"),
+			})
+			got, _, err := ClientCredentialFlow(context.Background(), fs, cl, tt.auth, false)
 			if tt.expErr {
 				require.Error(t, err)
 				return
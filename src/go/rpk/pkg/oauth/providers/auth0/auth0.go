@@ -69,6 +69,15 @@ func NewClient(overrides config.DevOverrides) *Client {
 	return cl
 }
 
+// WithCloudURL sets the cloud API URL the device flow talks to and returns the
+// client. An empty url keeps the current URL.
+func (cl *Client) WithCloudURL(url string) *Client {
+	if url != "" {
+		cl.cloudURL = url
+	}
+	return cl
+}
+
 func (*Client) URLOpener(url string) error {
 	return browser.OpenURL(url)
 }
@@ -0,0 +1,90 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package os
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// Writers within this process serialize on a per-path mutex; the advisory
+// file lock below only serializes across processes.
+var (
+	pathLocksMu sync.Mutex
+	pathLocks   = make(map[string]*sync.Mutex)
+)
+
+func pathLock(path string) *sync.Mutex {
+	pathLocksMu.Lock()
+	defer pathLocksMu.Unlock()
+	mu, ok := pathLocks[path]
+	if !ok {
+		mu = new(sync.Mutex)
+		pathLocks[path] = mu
+	}
+	return mu
+}
+
+// LockExclusive blocks until it acquires an exclusive lock on the given lock
+// file path and returns a function that releases the lock.
+//
+// If fs is backed by the OS filesystem, the lock file is created if needed and
+// an advisory lock is held on it (flock on Unix, LockFileEx on Windows) so
+// that separate processes serialize. The lock file is not removed when
+// unlocking; removing it would allow two processes to lock different files.
+// A lock file that we cannot open for writing, such as one left behind by
+// running rpk with sudo, is locked through a read only handle: the advisory
+// lock does not need write access.
+// For any other filesystem, such as an in-memory filesystem in tests, only
+// writers within this process are serialized.
+func LockExclusive(fs afero.Fs, path string) (unlock func() error, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	mu := pathLock(abs)
+	mu.Lock()
+
+	if _, ok := fs.(*afero.OsFs); !ok {
+		return func() error { mu.Unlock(); return nil }, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(abs, os.O_CREATE|os.O_RDWR, 0o644)
+	if errors.Is(err, os.ErrPermission) {
+		f, err = os.Open(abs)
+	}
+	if err != nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("unable to open lock file %q: %v", abs, err)
+	}
+	if err := flockExclusive(f); err != nil {
+		f.Close()
+		mu.Unlock()
+		return nil, fmt.Errorf("unable to lock %q: %v", abs, err)
+	}
+	return func() error {
+		defer mu.Unlock()
+		uerr := funlock(f)
+		cerr := f.Close()
+		if uerr != nil {
+			return uerr
+		}
+		return cerr
+	}, nil
+}
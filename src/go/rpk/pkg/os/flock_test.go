@@ -0,0 +1,35 @@
+// Copyright 2026 Redpanda Data, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package os_test
+
+import (
+	stdos "os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/redpanda-data/redpanda/src/go/rpk/pkg/os"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExclusiveReadOnlyLockFile(t *testing.T) {
+	if runtime.GOOS == "windows" || stdos.Geteuid() == 0 {
+		t.Skip("requires a lock file that the current user cannot write")
+	}
+	// As if a previous "sudo rpk" created the lock file: we can read
+	// it, but not write it.
+	path := filepath.Join(t.TempDir(), "rpk.yaml.lock")
+	require.NoError(t, stdos.WriteFile(path, nil, 0o444))
+
+	unlock, err := os.LockExclusive(afero.NewOsFs(), path)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
@@ -130,35 +130,8 @@ func IsTunerAvailable(tuner string) bool {
 }
 
 func IsTunerEnabled(tuner string, tuneCfg config.RpkNodeTuners) bool {
-	switch tuner {
-	case "disk_irq":
-		return tuneCfg.TuneDiskIrq
-	case "disk_scheduler":
-		return tuneCfg.TuneDiskScheduler
-	case "disk_nomerges":
-		return tuneCfg.TuneNomerges
-	case "disk_write_cache":
-		return tuneCfg.TuneDiskWriteCache
-	case "fstrim":
-		return tuneCfg.TuneFstrim
-	case "net":
-		return tuneCfg.TuneNetwork
-	case "cpu":
-		return tuneCfg.TuneCPU
-	case "aio_events":
-		return tuneCfg.TuneAioEvents
-	case "clocksource":
-		return tuneCfg.TuneClocksource
-	case "swappiness":
-		return tuneCfg.TuneSwappiness
-	case "transparent_hugepages":
-		return tuneCfg.TuneTransparentHugePages
-	case "coredump":
-		return tuneCfg.TuneCoredump
-	case "ballast_file":
-		return tuneCfg.TuneBallastFile
-	}
-	return false
+	enabled, _ := tuneCfg.IsEnabled(tuner)
+	return enabled
 }
 
 func (factory *tunersFactory) CreateTuner(